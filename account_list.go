@@ -1,7 +1,10 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
+	"iter"
+	"net/url"
 )
 
 //Account pager
@@ -14,30 +17,42 @@ type AccountList struct {
 
 //Get next set of accounts, will return false if no more accounts
 func (a *AccountList) Next() bool {
-	if a.next != "" {
-		*a, _ = a.r.GetAccounts(a.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return a.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (a *AccountList) NextCtx(ctx context.Context) bool {
+	return movePage(a, a.next, func() (AccountList, error) {
+		return a.r.GetAccountsCtx(ctx, a.NextParams())
+	})
 }
 
 //Get previous set of accounts, will return false if no previous accounts
 func (a *AccountList) Prev() bool {
-	if a.prev != "" {
-		*a, _ = a.r.GetAccounts(a.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return a.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (a *AccountList) PrevCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AccountList, error) {
+		return a.r.GetAccountsCtx(ctx, a.PrevParams())
+	})
 }
 
 //Go to start set of accounts, returns false if no valid records
 func (a *AccountList) Start() bool {
-	if a.prev != "" {
-		*a, _ = a.r.GetAccounts(a.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return a.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (a *AccountList) StartCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AccountList, error) {
+		return a.r.GetAccountsCtx(ctx, a.StartParams())
+	})
+}
+
+//Iterate over all accounts, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) Accounts(ctx context.Context, params ...url.Values) iter.Seq2[Account, error] {
+	return listAll[Account](ctx, r, ACCOUNTS, params)
 }
@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"time"
 )
@@ -25,7 +26,12 @@ type Account struct {
 
 //Load the billing information for this account
 func (a *Account) LoadBilling() error {
-	bi, err := a.r.GetBillingInfo(a.AccountCode)
+	return a.LoadBillingCtx(context.Background())
+}
+
+//Same as LoadBilling, bound to a context
+func (a *Account) LoadBillingCtx(ctx context.Context) error {
+	bi, err := a.r.GetBillingInfoCtx(ctx, a.AccountCode)
 	if a.B == nil {
 		a.B = new(BillingInfo)
 	}
@@ -35,30 +41,58 @@ func (a *Account) LoadBilling() error {
 
 //Return adjustments for this account
 func (a *Account) GetAdjustments() (AdjustmentList, error) {
-	return a.r.GetAdjustments(a.AccountCode)
+	return a.GetAdjustmentsCtx(context.Background())
+}
+
+//Same as GetAdjustments, bound to a context
+func (a *Account) GetAdjustmentsCtx(ctx context.Context) (AdjustmentList, error) {
+	return a.r.GetAdjustmentsCtx(ctx, a.AccountCode)
 }
 
 //Return invoices for this account
 func (a *Account) GetInvoices() (AccountInvoiceList, error) {
-	return a.r.GetAccountInvoices(a.AccountCode)
+	return a.GetInvoicesCtx(context.Background())
+}
+
+//Same as GetInvoices, bound to a context
+func (a *Account) GetInvoicesCtx(ctx context.Context) (AccountInvoiceList, error) {
+	return a.r.GetAccountInvoicesCtx(ctx, a.AccountCode)
 }
 
 //Return subscriptions for this account
 func (a *Account) GetSubscriptions() (AccountSubscriptionList, error) {
-	return a.r.GetAccountSubscriptions(a.AccountCode)
+	return a.GetSubscriptionsCtx(context.Background())
+}
+
+//Same as GetSubscriptions, bound to a context
+func (a *Account) GetSubscriptionsCtx(ctx context.Context) (AccountSubscriptionList, error) {
+	return a.r.GetAccountSubscriptionsCtx(ctx, a.AccountCode)
 }
 
 //Return transactions for this account
 func (a *Account) GetTransactions() (AccountTransactionList, error) {
-	return a.r.GetAccountTransactions(a.AccountCode)
+	return a.GetTransactionsCtx(context.Background())
+}
+
+//Same as GetTransactions, bound to a context
+func (a *Account) GetTransactionsCtx(ctx context.Context) (AccountTransactionList, error) {
+	return a.r.GetAccountTransactionsCtx(ctx, a.AccountCode)
 }
 
 //Create a new account and load updated fields
 func (a *Account) Create() error {
+	return a.CreateCtx(context.Background())
+}
+
+//Same as Create, bound to a context
+func (a *Account) CreateCtx(ctx context.Context) error {
 	if a.CreatedAt != nil || a.HostedLoginToken != "" || a.State != "" {
-		return RecurlyError{statusCode: 400, Description: "Account Code Already in Use"}
+		return &APIError{StatusCode: 400, Description: "Account Code Already in Use"}
+	}
+	if err := a.B.validate(); err != nil {
+		return err
 	}
-	err := a.r.doCreate(&a, a.endpoint)
+	err := a.r.doCreate(ctx, &a, a.endpoint)
 	if err == nil {
 		a.B = nil
 	}
@@ -67,29 +101,49 @@ func (a *Account) Create() error {
 
 //Update an account 
 func (a *Account) Update() error {
+	return a.UpdateCtx(context.Background())
+}
+
+//Same as Update, bound to a context
+func (a *Account) UpdateCtx(ctx context.Context) error {
 	newaccount := new(Account)
 	*newaccount = *a
 	newaccount.State = ""
 	newaccount.HostedLoginToken = ""
 	newaccount.CreatedAt = nil
 	newaccount.B = nil
-	return a.r.doUpdate(newaccount, a.endpoint+"/"+a.AccountCode)
+	return a.r.doUpdate(ctx, newaccount, a.endpoint+"/"+a.AccountCode)
 }
 
 //Close an account
 func (a *Account) Close() error {
-	return a.r.doDelete(a.endpoint + "/" + a.AccountCode)
+	return a.CloseCtx(context.Background())
+}
+
+//Same as Close, bound to a context
+func (a *Account) CloseCtx(ctx context.Context) error {
+	return a.r.doDelete(ctx, a.endpoint + "/" + a.AccountCode)
 }
 
 //Close an account
 func (a *Account) RemoveRedemption() error {
-	return a.r.doDelete(a.endpoint + "/" + a.AccountCode + "/redemption")
+	return a.RemoveRedemptionCtx(context.Background())
+}
+
+//Same as RemoveRedemption, bound to a context
+func (a *Account) RemoveRedemptionCtx(ctx context.Context) error {
+	return a.r.doDelete(ctx, a.endpoint + "/" + a.AccountCode + "/redemption")
 }
 
 //Reopen a closed account
 func (a *Account) Reopen() error {
+	return a.ReopenCtx(context.Background())
+}
+
+//Same as Reopen, bound to a context
+func (a *Account) ReopenCtx(ctx context.Context) error {
 	newaccount := new(Account)
-	return a.r.doUpdate(newaccount, a.endpoint+"/"+a.AccountCode+"/reopen")
+	return a.r.doUpdate(ctx, newaccount, a.endpoint+"/"+a.AccountCode+"/reopen")
 }
 
 //Account Stub struct
@@ -98,3 +152,8 @@ type AccountStub struct {
 	stub
 }
 
+//Bind the account to a client after it is decoded from a list
+func (a *Account) attach(r *Recurly) {
+	a.r = r
+	a.endpoint = ACCOUNTS
+}
@@ -1,10 +1,12 @@
 package gorecurly
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 var accountGet,accountCreate string
@@ -57,8 +59,7 @@ func TestGetAccount(t *testing.T) {
 	ts := httptest.NewServer(nil)
 	defer ts.Close()
 
-	r := InitRecurly("","")
-	r.url = "http://" + ts.Listener.Addr().String() + "/"
+	r := NewClient("", WithBaseURL(ts.URL))
 	if acc, e := r.GetAccount("test21"); e != nil {
 		t.Fatal(e.Error())
 	} else {
@@ -68,5 +69,21 @@ func TestGetAccount(t *testing.T) {
 	}
 }
 
+func TestGetAccountCtxDeadline(t *testing.T) {
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer ts.Close()
+	defer close(block)
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, e := r.GetAccountCtx(ctx, "test21"); e == nil {
+		t.Fatal("Expected the request to be canceled by the context deadline")
+	}
+}
+
 func TestCreate(t *testing.T) {
 }
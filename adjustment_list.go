@@ -1,7 +1,10 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
+	"iter"
+	"net/url"
 )
 
 //Adjustment Paging Struct
@@ -15,31 +18,42 @@ type AdjustmentList struct {
 
 //Get next set of adjustments
 func (a *AdjustmentList) Next() bool {
-	if a.next != "" {
-		*a, _ = a.r.GetAdjustments(a.AccountCode, a.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return a.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (a *AdjustmentList) NextCtx(ctx context.Context) bool {
+	return movePage(a, a.next, func() (AdjustmentList, error) {
+		return a.r.GetAdjustmentsCtx(ctx, a.AccountCode, a.NextParams())
+	})
 }
 
 //Get previous set of accounts
 func (a *AdjustmentList) Prev() bool {
-	if a.prev != "" {
-		*a, _ = a.r.GetAdjustments(a.AccountCode, a.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return a.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (a *AdjustmentList) PrevCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AdjustmentList, error) {
+		return a.r.GetAdjustmentsCtx(ctx, a.AccountCode, a.PrevParams())
+	})
 }
 
 //Go to start set of accounts
 func (a *AdjustmentList) Start() bool {
-	if a.prev != "" {
-		*a, _ = a.r.GetAdjustments(a.AccountCode, a.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return a.StartCtx(context.Background())
 }
 
+//Same as Start, bound to a context
+func (a *AdjustmentList) StartCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AdjustmentList, error) {
+		return a.r.GetAdjustmentsCtx(ctx, a.AccountCode, a.StartParams())
+	})
+}
+
+//Iterate over all adjustments for an account_code, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) Adjustments(ctx context.Context, account_code string, params ...url.Values) iter.Seq2[Adjustment, error] {
+	return listAll[Adjustment](ctx, r, ACCOUNTS+"/"+account_code+"/"+ADJUSTMENTS, params)
+}
@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"time"
@@ -11,40 +12,93 @@ type Adjustment struct {
 	XMLName           xml.Name `xml:"adjustment"`
 	endpoint          string
 	r                 *Recurly
-	Type              string       `xml:"type,attr"`
-	AccountCode       string       `xml:"-"`
-	Account           *AccountStub `xml:"account,omitempty"`
-	UUID              string       `xml:"uuid,omitempty"`
-	Description       string       `xml:"description,omitempty"`
-	AccountingCode    string       `xml:"accounting_code,omitempty"`
-	Origin            string       `xml:"origin,omitempty"`
-	UnitAmountInCents int          `xml:"unit_amount_in_cents,omitempty"`
-	Quantity          int          `xml:"quantity,omitempty"`
-	DiscountInCents   int          `xml:"discount_in_cents,omitempty"`
-	TaxInCents        int          `xml:"tax_in_cents,omitempty"`
-	Currency          string       `xml:"currency,omitempty"`
-	Taxable           bool         `xml:"taxable,omitempty"`
-	StartDate         *time.Time   `xml:"start_date,omitempty"`
-	EndDate           RecurlyDate  `xml:"end_date,omitempty"`
-	CreatedAt         *time.Time   `xml:"created_at,omitempty"`
+	Type              string            `xml:"type,attr"`
+	AccountCode       string            `xml:"-"`
+	Account           *AccountStub      `xml:"account,omitempty"`
+	Invoice           *InvoiceStub      `xml:"invoice,omitempty"`
+	Subscription      *SubscriptionStub `xml:"subscription,omitempty"`
+	UUID              string            `xml:"uuid,omitempty"`
+	Description       string            `xml:"description,omitempty"`
+	AccountingCode    string            `xml:"accounting_code,omitempty"`
+	Origin            string            `xml:"origin,omitempty"`
+	UnitAmountInCents int               `xml:"unit_amount_in_cents,omitempty"`
+	Quantity          int               `xml:"quantity,omitempty"`
+	DiscountInCents   int               `xml:"discount_in_cents,omitempty"`
+	TaxInCents        int               `xml:"tax_in_cents,omitempty"`
+	Currency          string            `xml:"currency,omitempty"`
+	Taxable           bool              `xml:"taxable,omitempty"`
+	StartDate         *time.Time        `xml:"start_date,omitempty"`
+	EndDate           RecurlyDate       `xml:"end_date,omitempty"`
+	CreatedAt         *time.Time        `xml:"created_at,omitempty"`
+	//Read only, filled on the line items of an invoice
+	State                  string      `xml:"state,omitempty"`
+	ProductCode            string      `xml:"product_code,omitempty"`
+	TotalInCents           int         `xml:"total_in_cents,omitempty"`
+	OriginalAdjustmentUUID string      `xml:"original_adjustment_uuid,omitempty"`
+	ProrationRate          float64     `xml:"proration_rate,omitempty"`
+	TaxExempt              bool        `xml:"tax_exempt,omitempty"`
+	TaxCode                string      `xml:"tax_code,omitempty"`
+	TaxType                string      `xml:"tax_type,omitempty"`
+	TaxRegion              string      `xml:"tax_region,omitempty"`
+	TaxRate                float64     `xml:"tax_rate,omitempty"`
+	TaxDetails             *TaxDetails `xml:"tax_details,omitempty"`
+}
+
+//Tax details of a line item, one per jurisdiction
+type TaxDetails struct {
+	Details []TaxDetail `xml:"tax_detail"`
+}
+
+//The tax of a line item in one jurisdiction, e.g. state or county
+type TaxDetail struct {
+	Name       string  `xml:"name,omitempty"`
+	Type       string  `xml:"type,omitempty"`
+	TaxRate    float64 `xml:"tax_rate,omitempty"`
+	TaxInCents int     `xml:"tax_in_cents,omitempty"`
+}
+
+//Whether the line item covers only part of its service period
+func (a *Adjustment) Prorated() bool {
+	return a.ProrationRate > 0 && a.ProrationRate < 1
 }
 
 //Create a new adjustment and load updated fields
 func (a *Adjustment) Create() error {
+	return a.CreateCtx(context.Background())
+}
+
+//Same as Create, bound to a context
+func (a *Adjustment) CreateCtx(ctx context.Context) error {
 	if a.UUID != "" {
-		return RecurlyError{statusCode: 400, Description: "Adjustment Already created"}
+		return &APIError{StatusCode: 400, Description: "Adjustment Already created"}
 	}
-	return a.r.doCreate(&a, ACCOUNTS+"/"+a.AccountCode+"/"+a.endpoint)
+	return a.r.doCreate(ctx, &a, ACCOUNTS+"/"+a.AccountCode+"/"+a.endpoint)
 }
 
 //Delete an adjustment
 func (a *Adjustment) Delete() error {
-	return a.r.doDelete(a.endpoint + "/" + a.UUID)
+	return a.DeleteCtx(context.Background())
+}
+
+//Same as Delete, bound to a context
+func (a *Adjustment) DeleteCtx(ctx context.Context) error {
+	return a.r.doDelete(ctx, a.endpoint + "/" + a.UUID)
 }
 
 func (a *Adjustment) GetAccount() (Account, error) {
+	return a.GetAccountCtx(context.Background())
+}
+
+//Same as GetAccount, bound to a context
+func (a *Adjustment) GetAccountCtx(ctx context.Context) (Account, error) {
 	if a.Account == nil {
 		return Account{}, errors.New("Account Stub is nil")
 	}
-	return a.r.GetAccount(a.Account.GetCode())
+	return a.r.GetAccountCtx(ctx, a.Account.GetCode())
+}
+
+//Bind the adjustment to a client after it is decoded from a list
+func (a *Adjustment) attach(r *Recurly) {
+	a.r = r
+	a.endpoint = ADJUSTMENTS
 }
@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 )
@@ -38,10 +39,170 @@ type BillingInfo struct {
 	Month              int          `xml:"month,omitempty"`
 	Year               int          `xml:"year,omitempty"`
 	BillingAgreementID string       `xml:"billing_agreement_id,omitempty"`
+	TokenID            string       `xml:"token_id,omitempty"`
+	//PayPal and Amazon billing agreements
+	PayPalBillingAgreementID string `xml:"paypal_billing_agreement_id,omitempty"`
+	AmazonBillingAgreementID string `xml:"amazon_billing_agreement_id,omitempty"`
+	AmazonRegion             string `xml:"amazon_region,omitempty"`
+	//Bank accounts, LastFour holds the end of the account number
+	NameOnAccount string `xml:"name_on_account,omitempty"`
+	AccountType   string `xml:"account_type,omitempty"`
+	RoutingNumber string `xml:"routing_number,omitempty"`
+	AccountNumber string `xml:"account_number,omitempty"`
+	//The payment method fields as they were loaded from Recurly
+	loaded *loadedPaymentMethod
+}
+
+//The fields of a payment method Recurly returns but only takes to set up a new payment method
+type loadedPaymentMethod struct {
+	PayPalBillingAgreementID string
+	AmazonBillingAgreementID string
+	AmazonRegion             string
+	NameOnAccount            string
+	AccountType              string
+	RoutingNumber            string
+}
+
+//Remember the payment method fields loaded from Recurly, Update leaves them out while they are unchanged
+func (b *BillingInfo) markLoaded() {
+	b.loaded = &loadedPaymentMethod{
+		b.PayPalBillingAgreementID,
+		b.AmazonBillingAgreementID,
+		b.AmazonRegion,
+		b.NameOnAccount,
+		b.AccountType,
+		b.RoutingNumber,
+	}
+}
+
+//Discriminates the payment methods of billing info
+type PaymentMethodType string
+
+const (
+	PaymentMethodCreditCard  PaymentMethodType = "credit_card"
+	PaymentMethodPayPal      PaymentMethodType = "paypal"
+	PaymentMethodAmazon      PaymentMethodType = "amazon"
+	PaymentMethodBankAccount PaymentMethodType = "bank_account"
+)
+
+//The payment method of billing info, one of *CreditCard, *PayPalBillingAgreement, *AmazonBillingAgreement or *BankAccount
+type PaymentMethod interface {
+	Type() PaymentMethodType
+}
+
+//A credit card, Number and VerificationValue are only sent, FirstSix, LastFour and CardType only received
+type CreditCard struct {
+	Number            string
+	VerificationValue string
+	Month             int
+	Year              int
+	FirstSix          string
+	LastFour          string
+	CardType          string
+}
+
+func (c *CreditCard) Type() PaymentMethodType {
+	return PaymentMethodCreditCard
+}
+
+//A PayPal reference transaction billing agreement
+type PayPalBillingAgreement struct {
+	BillingAgreementID string
+}
+
+func (p *PayPalBillingAgreement) Type() PaymentMethodType {
+	return PaymentMethodPayPal
+}
+
+//An Amazon Pay billing agreement, Region is one of us, eu or uk
+type AmazonBillingAgreement struct {
+	BillingAgreementID string
+	Region             string
+}
+
+func (a *AmazonBillingAgreement) Type() PaymentMethodType {
+	return PaymentMethodAmazon
+}
+
+//Bank account types
+const (
+	BankAccountChecking = "checking"
+	BankAccountSavings  = "savings"
+)
+
+//An ACH bank account, AccountNumber is only sent and LastFour only received
+type BankAccount struct {
+	NameOnAccount string
+	AccountType   string
+	RoutingNumber string
+	AccountNumber string
+	LastFour      string
+}
+
+func (b *BankAccount) Type() PaymentMethodType {
+	return PaymentMethodBankAccount
+}
+
+//Return the payment method the billing info holds, nil when it has none, e.g. only a token
+func (b BillingInfo) PaymentMethod() PaymentMethod {
+	switch {
+	case b.NameOnAccount != "" || b.RoutingNumber != "" || b.AccountNumber != "" || b.AccountType != "":
+		return &BankAccount{b.NameOnAccount, b.AccountType, b.RoutingNumber, b.AccountNumber, b.LastFour}
+	case b.AmazonBillingAgreementID != "":
+		return &AmazonBillingAgreement{b.AmazonBillingAgreementID, b.AmazonRegion}
+	case b.PayPalBillingAgreementID != "":
+		return &PayPalBillingAgreement{b.PayPalBillingAgreementID}
+	case b.BillingAgreementID != "":
+		return &PayPalBillingAgreement{b.BillingAgreementID}
+	case b.Number != "" || b.LastFour != "" || b.CardType != "" || b.Month != 0:
+		return &CreditCard{b.Number, b.VerificationValue, b.Month, b.Year, b.FirstSix, b.LastFour, b.CardType}
+	}
+	return nil
+}
+
+//Replace the payment method of the billing info, the fields of other payment methods are cleared
+func (b *BillingInfo) SetPaymentMethod(m PaymentMethod) {
+	b.Number, b.VerificationValue, b.Month, b.Year = "", "", 0, 0
+	b.FirstSix, b.LastFour, b.CardType = "", "", ""
+	b.BillingAgreementID, b.PayPalBillingAgreementID = "", ""
+	b.AmazonBillingAgreementID, b.AmazonRegion = "", ""
+	b.NameOnAccount, b.AccountType, b.RoutingNumber, b.AccountNumber = "", "", "", ""
+	switch t := m.(type) {
+	case *CreditCard:
+		b.Number, b.VerificationValue, b.Month, b.Year = t.Number, t.VerificationValue, t.Month, t.Year
+	case *PayPalBillingAgreement:
+		b.PayPalBillingAgreementID = t.BillingAgreementID
+	case *AmazonBillingAgreement:
+		b.AmazonBillingAgreementID, b.AmazonRegion = t.BillingAgreementID, t.Region
+	case *BankAccount:
+		b.NameOnAccount, b.AccountType, b.RoutingNumber, b.AccountNumber = t.NameOnAccount, t.AccountType, t.RoutingNumber, t.AccountNumber
+	}
+}
+
+//Returned instead of sending billing info that has both a Recurly.js token and card data
+var ErrCardDataWithToken = errors.New("Billing info with a token_id must not carry a card number or verification value.")
+
+//Refuse raw card data when the card is given by a token, so it never leaves the server by accident
+func (b *BillingInfo) validate() error {
+	if b == nil || b.TokenID == "" {
+		return nil
+	}
+	if b.Number != "" || b.VerificationValue != "" {
+		return ErrCardDataWithToken
+	}
+	return nil
 }
 
 //Update an billing info 
 func (b *BillingInfo) Update() error {
+	return b.UpdateCtx(context.Background())
+}
+
+//Same as Update, bound to a context
+func (b *BillingInfo) UpdateCtx(ctx context.Context) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
 	newbilling := new(BillingInfo)
 	*newbilling = *b
 	newbilling.AccountCode = ""
@@ -49,11 +210,36 @@ func (b *BillingInfo) Update() error {
 	newbilling.FirstSix = ""
 	newbilling.LastFour = ""
 	newbilling.CardType = ""
-	return b.r.doUpdate(newbilling, ACCOUNTS+"/"+b.Account.GetCode()+"/"+BILLINGINFO)
+	if newbilling.TokenID != "" {
+		//the expiration date of the stored card, the token carries the new one
+		newbilling.Month = 0
+		newbilling.Year = 0
+	}
+	if l := newbilling.loaded; l != nil {
+		//the agreements and bank account loaded from Recurly, sending them back would set up a new payment method
+		if newbilling.PayPalBillingAgreementID == l.PayPalBillingAgreementID {
+			newbilling.PayPalBillingAgreementID = ""
+		}
+		if newbilling.AmazonBillingAgreementID == l.AmazonBillingAgreementID && newbilling.AmazonRegion == l.AmazonRegion {
+			newbilling.AmazonBillingAgreementID = ""
+			newbilling.AmazonRegion = ""
+		}
+		if newbilling.AccountNumber == "" && newbilling.NameOnAccount == l.NameOnAccount && newbilling.AccountType == l.AccountType && newbilling.RoutingNumber == l.RoutingNumber {
+			newbilling.NameOnAccount = ""
+			newbilling.AccountType = ""
+			newbilling.RoutingNumber = ""
+		}
+	}
+	return b.r.doUpdate(ctx, newbilling, ACCOUNTS+"/"+b.Account.GetCode()+"/"+BILLINGINFO)
 }
 
 //Delete billing info for an account
 func (b *BillingInfo) Delete() error {
+	return b.DeleteCtx(context.Background())
+}
+
+//Same as Delete, bound to a context
+func (b *BillingInfo) DeleteCtx(ctx context.Context) error {
 	var code string
 	code = b.AccountCode
 	if b.Account != nil {
@@ -62,10 +248,15 @@ func (b *BillingInfo) Delete() error {
 	if code == "" {
 		return errors.New("No Account Code associated with this account")
 	}
-	return b.r.doDelete(ACCOUNTS + "/" + code + "/" + BILLINGINFO)
+	return b.r.doDelete(ctx, ACCOUNTS + "/" + code + "/" + BILLINGINFO)
 }
 
 //This function will return the parent Account object
 func (b BillingInfo) GetAccount() (Account, error) {
-	return b.r.GetAccount(b.Account.GetCode())
+	return b.GetAccountCtx(context.Background())
+}
+
+//Same as GetAccount, bound to a context
+func (b BillingInfo) GetAccountCtx(ctx context.Context) (Account, error) {
+	return b.r.GetAccountCtx(ctx, b.Account.GetCode())
 }
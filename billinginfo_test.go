@@ -0,0 +1,185 @@
+package gorecurly
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBillingInfoToken(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.WriteHeader(201)
+		if strings.HasPrefix(r.URL.Path, "/accounts") {
+			fmt.Fprint(w, accountCreate)
+		}
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	acc := r.NewAccount()
+	acc.AccountCode = "abcdef1234567890"
+	acc.B = &BillingInfo{TokenID: "7z6furn4jvb9"}
+	if err := acc.Create(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(bodies) != 1 || !strings.Contains(bodies[0], "<token_id>7z6furn4jvb9</token_id>") || strings.Contains(bodies[0], "<number>") {
+		t.Fatalf("Unexpected request %v", bodies)
+	}
+
+	acc = r.NewAccount()
+	acc.B = &BillingInfo{TokenID: "7z6furn4jvb9", Number: "4111111111111111"}
+	sub := r.NewSubscription()
+	sub.AttachAccount(acc)
+	tran := r.NewTransaction()
+	tran.AttachAccount(acc)
+	bi := r.NewBillingInfo()
+	bi.Account = &AccountStub{}
+	bi.TokenID = "7z6furn4jvb9"
+	bi.VerificationValue = "123"
+	for name, create := range map[string]func() error{"account": acc.Create, "subscription": sub.Create, "transaction": tran.Create, "billing info": bi.Update} {
+		if err := create(); !errors.Is(err, ErrCardDataWithToken) {
+			t.Fatalf("Expected the %v with card data and a token to be refused, got %v", name, err)
+		}
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("Expected no card data to be sent, got %v", bodies[1:])
+	}
+}
+
+func TestBillingInfoPaymentMethod(t *testing.T) {
+	var bi BillingInfo
+	err := xml.Unmarshal([]byte(`<billing_info type="bank_account">
+		<first_name>Verena</first_name>
+		<name_on_account>Verena Example</name_on_account>
+		<account_type>checking</account_type>
+		<last_four>5678</last_four>
+		<routing_number>123456780</routing_number>
+	</billing_info>`), &bi)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	bank, ok := bi.PaymentMethod().(*BankAccount)
+	if !ok || bank.Type() != PaymentMethodBankAccount || bank.LastFour != "5678" || bank.AccountType != BankAccountChecking {
+		t.Fatalf("Unexpected payment method %#v", bi.PaymentMethod())
+	}
+
+	bi = BillingInfo{Number: "4111111111111111", Month: 12, Year: 2030}
+	if m := bi.PaymentMethod(); m == nil || m.Type() != PaymentMethodCreditCard {
+		t.Fatalf("Unexpected payment method %#v", m)
+	}
+	bi.SetPaymentMethod(&AmazonBillingAgreement{"C01-1234567-8", "eu"})
+	out, _ := xml.Marshal(bi)
+	if string(out) != "<billing_info><amazon_billing_agreement_id>C01-1234567-8</amazon_billing_agreement_id><amazon_region>eu</amazon_region></billing_info>" {
+		t.Fatalf("Unexpected billing info %s", out)
+	}
+	bi.SetPaymentMethod(&PayPalBillingAgreement{"B-1234"})
+	if m, ok := bi.PaymentMethod().(*PayPalBillingAgreement); !ok || m.BillingAgreementID != "B-1234" || bi.AmazonRegion != "" {
+		t.Fatalf("Unexpected payment method %#v", bi.PaymentMethod())
+	}
+	if (BillingInfo{TokenID: "7z6furn4jvb9"}).PaymentMethod() != nil {
+		t.Fatal("Expected no payment method for a token")
+	}
+}
+
+func TestBillingInfoTokenUpdate(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		fmt.Fprint(w, `<billing_info href="https://api.recurly.com/v2/accounts/1/billing_info" type="credit_card">
+			<account href="https://api.recurly.com/v2/accounts/1"/>
+			<first_name>Verena</first_name>
+			<first_six>411111</first_six>
+			<last_four>1111</last_four>
+			<card_type>Visa</card_type>
+			<month type="integer">11</month>
+			<year type="integer">2030</year>
+		</billing_info>`)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	bi, err := r.GetBillingInfo("1")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	bi.TokenID = "7z6furn4jvb9"
+	if err := bi.Update(); err != nil {
+		t.Fatalf("Expected the stored card's expiration date to be accepted with a token, got %v", err)
+	}
+	if !strings.Contains(body, "<token_id>7z6furn4jvb9</token_id>") || strings.Contains(body, "<month>") || strings.Contains(body, "<year>") {
+		t.Fatalf("Unexpected request %v", body)
+	}
+}
+
+func TestBillingInfoUpdateReceivedPaymentMethod(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		fmt.Fprint(w, `<billing_info href="https://api.recurly.com/v2/accounts/1/billing_info" type="bank_account">
+			<account href="https://api.recurly.com/v2/accounts/1"/>
+			<first_name>Verena</first_name>
+			<name_on_account>Verena Example</name_on_account>
+			<account_type>checking</account_type>
+			<last_four>5678</last_four>
+			<routing_number>123456780</routing_number>
+			<paypal_billing_agreement_id>B-1234</paypal_billing_agreement_id>
+		</billing_info>`)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	bi, err := r.GetBillingInfo("1")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	bi.Address1 = "1 Main St"
+	if err := bi.Update(); err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, field := range []string{"name_on_account", "account_type", "routing_number", "paypal_billing_agreement_id", "last_four"} {
+		if strings.Contains(body, "<"+field+">") {
+			t.Fatalf("Expected the received %v to be left out, got %v", field, body)
+		}
+	}
+
+	bi.SetPaymentMethod(&BankAccount{NameOnAccount: "Verena Example", AccountType: BankAccountSavings, RoutingNumber: "123456780", AccountNumber: "111222333"})
+	if err := bi.Update(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(body, "<account_number>111222333</account_number>") || !strings.Contains(body, "<routing_number>123456780</routing_number>") {
+		t.Fatalf("Expected the new bank account to be sent, got %v", body)
+	}
+
+	//a changed agreement is sent
+	bi, _ = r.GetBillingInfo("1")
+	bi.PayPalBillingAgreementID = "B-5678"
+	if err := bi.Update(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(body, "<paypal_billing_agreement_id>B-5678</paypal_billing_agreement_id>") || strings.Contains(body, "<routing_number>") {
+		t.Fatalf("Expected only the new agreement to be sent, got %v", body)
+	}
+
+	//fields set on billing info that was not loaded are sent as they are
+	bi = r.NewBillingInfo()
+	bi.Account = &AccountStub{}
+	bi.Account.HREF = "https://api.recurly.com/v2/accounts/1"
+	bi.PayPalBillingAgreementID = "B-123"
+	bi.AmazonRegion = "eu"
+	if err := bi.Update(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(body, "<paypal_billing_agreement_id>B-123</paypal_billing_agreement_id>") || !strings.Contains(body, "<amazon_region>eu</amazon_region>") {
+		t.Fatalf("Expected the fields set directly to be sent, got %v", body)
+	}
+}
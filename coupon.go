@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"time"
 )
@@ -47,10 +48,15 @@ type createCoupon struct {
 
 //Create a new coupon
 func (c *Coupon) Create() error {
+	return c.CreateCtx(context.Background())
+}
+
+//Same as Create, bound to a context
+func (c *Coupon) CreateCtx(ctx context.Context) error {
 	if c.CreatedAt != nil {
-		return RecurlyError{statusCode: 400, Description: "Coupon Already created"}
+		return &APIError{StatusCode: 400, Description: "Coupon Already created"}
 	}
-	//return c.r.doCreate(&c, c.endpoint)
+	//return c.r.doCreate(ctx, &c, c.endpoint)
 	cc := createCoupon{
 		CouponCode:         c.CouponCode,
 		Name:               c.Name,
@@ -69,19 +75,29 @@ func (c *Coupon) Create() error {
 	if err == nil {
 		cc.RedeemByDate = &gd
 	}
-	return c.r.doCreateReturn(cc, &c, c.endpoint)
+	return c.r.doCreateReturn(ctx, cc, &c, c.endpoint)
 }
 
 //Redeem a coupon on an account
 func (c *Coupon) Redeem(account_code string, currency string) error {
+	return c.RedeemCtx(context.Background(), account_code, currency)
+}
+
+//Same as Redeem, bound to a context
+func (c *Coupon) RedeemCtx(ctx context.Context, account_code string, currency string) error {
 	redemption := Redemption{AccountCode: account_code, Currency: currency}
 	redemption.r = c.r
-	return redemption.r.doCreate(&redemption, c.endpoint+"/"+c.CouponCode+"/redeem")
+	return redemption.r.doCreate(ctx, &redemption, c.endpoint+"/"+c.CouponCode+"/redeem")
 }
 
 //Deactivate a coupon
 func (c *Coupon) Deactivate() error {
-	return c.r.doDelete(c.endpoint + "/" + c.CouponCode)
+	return c.DeactivateCtx(context.Background())
+}
+
+//Same as Deactivate, bound to a context
+func (c *Coupon) DeactivateCtx(ctx context.Context) error {
+	return c.r.doDelete(ctx, c.endpoint + "/" + c.CouponCode)
 }
 
 //Coupon Stub struct
@@ -90,3 +106,8 @@ type CouponStub struct {
 	stub
 }
 
+//Bind the coupon to a client after it is decoded from a list
+func (c *Coupon) attach(r *Recurly) {
+	c.r = r
+	c.endpoint = COUPONS
+}
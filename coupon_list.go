@@ -1,7 +1,10 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
+	"iter"
+	"net/url"
 )
 
 //Coupon List Struct
@@ -14,30 +17,42 @@ type CouponList struct {
 
 //Get next set of Coupons
 func (c *CouponList) Next() bool {
-	if c.next != "" {
-		*c, _ = c.r.GetCoupons(c.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return c.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (c *CouponList) NextCtx(ctx context.Context) bool {
+	return movePage(c, c.next, func() (CouponList, error) {
+		return c.r.GetCouponsCtx(ctx, c.NextParams())
+	})
 }
 
 //Get previous set of coupons
 func (c *CouponList) Prev() bool {
-	if c.prev != "" {
-		*c, _ = c.r.GetCoupons(c.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return c.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (c *CouponList) PrevCtx(ctx context.Context) bool {
+	return movePage(c, c.prev, func() (CouponList, error) {
+		return c.r.GetCouponsCtx(ctx, c.PrevParams())
+	})
 }
 
 //Go to start set of coupons
 func (c *CouponList) Start() bool {
-	if c.prev != "" {
-		*c, _ = c.r.GetCoupons(c.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return c.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (c *CouponList) StartCtx(ctx context.Context) bool {
+	return movePage(c, c.prev, func() (CouponList, error) {
+		return c.r.GetCouponsCtx(ctx, c.StartParams())
+	})
+}
+
+//Iterate over all coupons, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) Coupons(ctx context.Context, params ...url.Values) iter.Seq2[Coupon, error] {
+	return listAll[Coupon](ctx, r, COUPONS, params)
 }
@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"time"
 )
@@ -20,6 +21,11 @@ type Redemption struct {
 
 //Remove a coupon from an account
 func (r *Redemption) Delete() error {
-	return r.r.doDelete(ACCOUNTS + "/" + r.Account.GetCode() + "/redemption")
+	return r.DeleteCtx(context.Background())
+}
+
+//Same as Delete, bound to a context
+func (r *Redemption) DeleteCtx(ctx context.Context) error {
+	return r.r.doDelete(ctx, ACCOUNTS + "/" + r.Account.GetCode() + "/redemption")
 }
 
@@ -0,0 +1,256 @@
+package gorecurly
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+//Recurly errors, an *APIError matches the one for its status code with errors.Is
+var (
+	ErrBadRequest         = errors.New("The request was invalid or could not be understood by the server. Resubmitting the request will likely result in the same error.")
+	ErrUnauthorized       = errors.New("Your API key is missing or invalid.")
+	ErrPaymentRequired    = errors.New("Your Recurly account is in production mode but is not in good standing. Please pay any outstanding invoices.")
+	ErrForbidden          = errors.New("The login is attempting to perform an action it does not have privileges to access. Verify your login credentials are for the appropriate account.")
+	ErrNotFound           = errors.New("The resource was not found with the given identifier. The response body will explain which resource was not found.")
+	ErrMethodNotAllowed   = errors.New("The requested method is not valid at the given URL.")
+	ErrNotAcceptable      = errors.New("The request's Accept header is not set to application/xml")
+	ErrPreconditionFailed = errors.New("The request was unsuccessful because a condition was not met. For example, this message may be returned if you attempt to cancel a subscription for an account that has no subscription.")
+	ErrValidation         = errors.New("The request could not be processed because of validation errors.")
+	ErrTooManyRequests    = errors.New("You have made too many API requests in the last hour. Future API requests will be ignored until the beginning of the next hour.")
+	ErrServer             = errors.New("Recurly experienced an internal error.")
+)
+
+//Recurly errors by status code, kept for compatibility.
+//Compare with errors.Is, an *APIError is returned rather than these values.
+var (
+	Error400 = ErrBadRequest
+	Error401 = ErrUnauthorized
+	Error402 = ErrPaymentRequired
+	Error403 = ErrForbidden
+	Error404 = ErrNotFound
+	Error405 = ErrMethodNotAllowed
+	Error406 = ErrNotAcceptable
+	Error412 = ErrPreconditionFailed
+	Error429 = ErrTooManyRequests
+)
+
+//Return the sentinel error for a status code, nil if there is none
+func statusError(code int) error {
+	switch code {
+	case 400:
+		return ErrBadRequest
+	case 401:
+		return ErrUnauthorized
+	case 402:
+		return ErrPaymentRequired
+	case 403:
+		return ErrForbidden
+	case 404:
+		return ErrNotFound
+	case 405:
+		return ErrMethodNotAllowed
+	case 406:
+		return ErrNotAcceptable
+	case 412:
+		return ErrPreconditionFailed
+	case 422:
+		return ErrValidation
+	case 429:
+		return ErrTooManyRequests
+	}
+	if code >= 500 {
+		return ErrServer
+	}
+	return nil
+}
+
+//An error returned by the Recurly api, or detected before sending the request
+type APIError struct {
+	XMLName     xml.Name `xml:"error"`
+	StatusCode  int      `xml:"-"`
+	Symbol      string   `xml:"symbol"`
+	Description string   `xml:"description"`
+	Details     string   `xml:"details"`
+	//The request that failed
+	Method string `xml:"-"`
+	URL    string `xml:"-"`
+	//The raw response body
+	Body []byte `xml:"-"`
+	//The detailed error, RecurlyValidationErrors for a 422 response
+	//or *TransactionError when a transaction was declined
+	Err error `xml:"-"`
+}
+
+//Recurly Generic Errors, kept for compatibility
+type RecurlyError = APIError
+
+//Formatted General Error
+func (e APIError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	msg := fmt.Sprintf("Recurly Error: %s , %s %s Status Code: %v", e.Symbol, e.Description, e.Details, e.StatusCode)
+	if e.URL != "" {
+		msg += fmt.Sprintf(" (%s %s)", e.Method, e.URL)
+	}
+	return msg
+}
+
+//Match the sentinel error of the status code, e.g. errors.Is(err, ErrNotFound)
+func (e APIError) Is(target error) bool {
+	return target != nil && statusError(e.StatusCode) == target
+}
+
+//Return the detailed error, for errors.As into RecurlyValidationErrors or *TransactionError
+func (e APIError) Unwrap() error {
+	return e.Err
+}
+
+//Recurly Validation Errors Array
+type RecurlyValidationErrors struct {
+	XMLName    xml.Name                 `xml:"errors"`
+	StatusCode int                      `xml:"-"`
+	Errors     []RecurlyValidationError `xml:"error"`
+}
+
+//Recurly validation error
+type RecurlyValidationError struct {
+	XMLName     xml.Name `xml:"error"`
+	FieldName   string   `xml:"field,attr"`
+	Symbol      string   `xml:"symbol,attr"`
+	Description string   `xml:",innerxml"`
+}
+
+//Formatted Validation Error
+func (r RecurlyValidationErrors) Error() string {
+	var rtnString string
+	for _, v := range r.Errors {
+		rtnString += v.FieldName + " " + v.Description + "\n"
+	}
+	return fmt.Sprintf("You have the following validation errors:\n%s", rtnString)
+}
+
+//A declined or failed transaction, returned with a 422 when a card is charged
+//by creating an account, subscription, transaction or updating billing info
+type TransactionError struct {
+	XMLName          xml.Name `xml:"transaction_error"`
+	ErrorCode        string   `xml:"error_code"`
+	ErrorCategory    string   `xml:"error_category"`
+	MerchantMessage  string   `xml:"merchant_message"`
+	CustomerMessage  string   `xml:"customer_message"`
+	GatewayErrorCode string   `xml:"gateway_error_code"`
+	//The failed transaction, nil if Recurly did not return it
+	Transaction *Transaction `xml:"-"`
+	//The validation errors returned along with the transaction error
+	Errors RecurlyValidationErrors `xml:"-"`
+}
+
+//Formatted Transaction Error
+func (t *TransactionError) Error() string {
+	return fmt.Sprintf("Recurly Transaction Error: %s (%s) %s", t.ErrorCode, t.ErrorCategory, t.MerchantMessage)
+}
+
+//Return the validation errors, for errors.As into RecurlyValidationErrors
+func (t *TransactionError) Unwrap() error {
+	return t.Errors
+}
+
+//The errors document of a 422 response
+type errorsDocument struct {
+	XMLName          xml.Name                 `xml:"errors"`
+	Errors           []RecurlyValidationError `xml:"error"`
+	TransactionError *TransactionError        `xml:"transaction_error"`
+	Transaction      *Transaction             `xml:"transaction"`
+}
+
+//Parse Recurly XML to create a Recurly Error.
+//Deprecated: errors returned by the client already are an *APIError.
+func CreateRecurlyStandardError(resp *http.Response) RecurlyError {
+	e := newAPIError(resp)
+	if xmlerr := xml.Unmarshal(e.Body, e); xmlerr != nil {
+		e.Description = string(e.Body)
+	}
+	return *e
+}
+
+//Parse Recurly XML to create a Validation Error.
+//Deprecated: use errors.As on an error returned by the client.
+func CreateRecurlyValidationError(resp *http.Response) (r RecurlyValidationErrors) {
+	e := parseRecurlyError(resp)
+	errors.As(e, &r)
+	r.StatusCode = e.StatusCode
+	return r
+}
+
+//Create an APIError for a response and read its body
+func newAPIError(resp *http.Response) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		e.URL = resp.Request.URL.String()
+	}
+	defer resp.Body.Close()
+	if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
+		e.Body = body
+	}
+	return e
+}
+
+//Create the error for a failed response from its body
+func (r *Recurly) createRecurlyError(resp *http.Response) error {
+	e := parseRecurlyError(resp)
+	var te *TransactionError
+	if errors.As(e, &te) && te.Transaction != nil {
+		te.Transaction.attach(r)
+	}
+	return e
+}
+
+//Parse the error of a failed response, a failed transaction is not bound to a client
+func parseRecurlyError(resp *http.Response) *APIError {
+	e := newAPIError(resp)
+	var root struct {
+		XMLName xml.Name
+	}
+	if len(bytes.TrimSpace(e.Body)) == 0 || xml.Unmarshal(e.Body, &root) != nil {
+		if sentinel := statusError(e.StatusCode); sentinel != nil {
+			e.Description = sentinel.Error()
+		}
+		if len(bytes.TrimSpace(e.Body)) > 0 {
+			e.Details = string(e.Body)
+		}
+		return e
+	}
+	switch root.XMLName.Local {
+	case "errors":
+		doc := errorsDocument{}
+		if xmlerr := xml.Unmarshal(e.Body, &doc); xmlerr != nil {
+			e.Description = xmlerr.Error()
+			return e
+		}
+		verrs := RecurlyValidationErrors{StatusCode: e.StatusCode, Errors: doc.Errors}
+		if len(verrs.Errors) > 0 {
+			e.Symbol = verrs.Errors[0].Symbol
+			e.Description = verrs.Errors[0].Description
+		}
+		e.Err = verrs
+		if te := doc.TransactionError; te != nil {
+			te.Errors = verrs
+			te.Transaction = doc.Transaction
+			e.Symbol = te.ErrorCode
+			e.Description = te.MerchantMessage
+			e.Err = te
+		}
+	case "error":
+		if xmlerr := xml.Unmarshal(e.Body, e); xmlerr != nil {
+			e.Description = xmlerr.Error()
+		}
+	default:
+		e.Description = string(e.Body)
+	}
+	return e
+}
@@ -0,0 +1,161 @@
+package gorecurly
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/accounts/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		fmt.Fprintf(w, "%s", `<?xml version="1.0" encoding="UTF-8"?>
+			<error>
+				<symbol>not_found</symbol>
+				<description lang="en-US">Couldn't find Account with account_code = missing</description>
+			</error>`)
+	})
+	mux.HandleFunc("/accounts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, "%s", `<?xml version="1.0" encoding="UTF-8"?>
+			<errors>
+				<error field="account.account_code" symbol="taken">has already been taken</error>
+				<error field="account.email" symbol="invalid_email">is not a valid email address</error>
+			</errors>`)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	r := NewClient("", WithBaseURL(ts.URL))
+
+	_, err := r.GetAccount("missing")
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, Error404) || errors.Is(err, ErrBadRequest) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	var apierr *APIError
+	if !errors.As(err, &apierr) {
+		t.Fatal("Expected an *APIError")
+	}
+	if apierr.StatusCode != 404 || apierr.Symbol != "not_found" || apierr.Method != "GET" || apierr.URL != ts.URL+"/accounts/missing" || len(apierr.Body) == 0 {
+		t.Fatalf("Unexpected error fields %+v", apierr)
+	}
+
+	acc := r.NewAccount()
+	acc.AccountCode = "taken"
+	err = acc.Create()
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	var verrs RecurlyValidationErrors
+	if !errors.As(err, &verrs) || len(verrs.Errors) != 2 || verrs.Errors[1].Symbol != "invalid_email" {
+		t.Fatalf("Expected the validation errors to be parsed, got %+v", verrs)
+	}
+
+	acc.State = "active"
+	if err = acc.Create(); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("Expected a bad request error, got %v", err)
+	}
+}
+
+func TestTransactionError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(422)
+		fmt.Fprintf(w, "%s", `<?xml version="1.0" encoding="UTF-8"?>
+			<errors>
+				<transaction_error>
+					<error_code>insufficient_funds</error_code>
+					<error_category>soft</error_category>
+					<merchant_message>The card has insufficient funds to cover the cost of the transaction.</merchant_message>
+					<customer_message>Your card has insufficient funds.</customer_message>
+					<gateway_error_code nil="nil"></gateway_error_code>
+				</transaction_error>
+				<error field="transaction.account.base" symbol="insufficient_funds">The card has insufficient funds to cover the cost of the transaction.</error>
+				<transaction href="https://api.recurly.com/v2/transactions/a13acd8fe4294916b79aec87b7ea441f" type="credit_card">
+					<uuid>a13acd8fe4294916b79aec87b7ea441f</uuid>
+					<action>purchase</action>
+					<amount_in_cents type="integer">1000</amount_in_cents>
+					<currency>USD</currency>
+					<status>declined</status>
+				</transaction>
+			</errors>`)
+	}))
+	defer ts.Close()
+	r := NewClient("", WithBaseURL(ts.URL))
+
+	tran := r.NewTransaction()
+	tran.AmountInCents = 1000
+	tran.Currency = "USD"
+	err := tran.Create()
+	var te *TransactionError
+	if !errors.As(err, &te) {
+		t.Fatalf("Expected a transaction error, got %v", err)
+	}
+	if te.ErrorCode != "insufficient_funds" || te.ErrorCategory != "soft" || te.CustomerMessage != "Your card has insufficient funds." {
+		t.Fatalf("Unexpected transaction error %+v", te)
+	}
+	if te.Transaction == nil || te.Transaction.UUID != "a13acd8fe4294916b79aec87b7ea441f" || te.Transaction.Status != "declined" {
+		t.Fatalf("Expected the declined transaction, got %+v", te.Transaction)
+	}
+	var verrs RecurlyValidationErrors
+	if !errors.As(err, &verrs) || len(verrs.Errors) != 1 || !errors.Is(err, ErrValidation) {
+		t.Fatal("Expected the validation errors to be reachable")
+	}
+}
+
+func TestValidationErrorFields(t *testing.T) {
+	verrs := RecurlyValidationErrors{Errors: []RecurlyValidationError{
+		{FieldName: "subscription.account.billing_info.year", Symbol: "expired", Description: "is expired"},
+		{FieldName: "subscription.account.billing_info.number", Symbol: "invalid", Description: "is not a valid credit card number"},
+		{FieldName: "subscription.account.billing_info.number", Symbol: "blank", Description: "can't be blank"},
+		{FieldName: "subscription.plan_code", Symbol: "invalid", Description: "is invalid"},
+		{FieldName: "transaction.account.base", Symbol: "declined", Description: "was declined"},
+	}}
+	if got := verrs.ForField("billing_info.number"); len(got) != 2 {
+		t.Fatalf("Expected 2 errors for billing_info.number, got %v", len(got))
+	}
+	if got := verrs.ForField("number"); len(got) != 2 {
+		t.Fatalf("Expected a trailing field name to match, got %v", len(got))
+	}
+	if got := verrs.ForField("info.number"); len(got) != 0 {
+		t.Fatal("Only whole field names should match")
+	}
+	if fields := verrs.Fields(); len(fields) != 4 || fields[1] != "subscription.account.billing_info.number" {
+		t.Fatalf("Unexpected fields %v", fields)
+	}
+	if !verrs.Has("expired") || verrs.Has("taken") {
+		t.Fatal("Has did not match the symbols")
+	}
+	expected := []string{
+		"Subscription.EmbedAccount.B.Year",
+		"Subscription.EmbedAccount.B.Number",
+		"Subscription.EmbedAccount.B.Number",
+		"Subscription.PlanCode",
+		"Transaction.EmbedAccount",
+	}
+	for k, e := range verrs.Errors {
+		if e.StructField() != expected[k] {
+			t.Fatalf("Expected %s, got %s", expected[k], e.StructField())
+		}
+	}
+	if got := verrs.ForStructField("B.Number"); len(got) != 2 {
+		t.Fatalf("Expected 2 errors for B.Number, got %v", len(got))
+	}
+}
+
+func TestDeprecatedErrorParsers(t *testing.T) {
+	response := func(code int, body string) *http.Response {
+		return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader(body))}
+	}
+	var standard RecurlyError = CreateRecurlyStandardError(response(500, `<error><symbol>oops</symbol><description>Broken</description></error>`))
+	if standard.StatusCode != 500 || standard.Symbol != "oops" || standard.Description != "Broken" {
+		t.Fatalf("Unexpected error %+v", standard)
+	}
+	verrs := CreateRecurlyValidationError(response(422, `<errors><error field="account.account_code" symbol="taken">has already been taken</error></errors>`))
+	if verrs.StatusCode != 422 || len(verrs.Errors) != 1 || verrs.Errors[0].Symbol != "taken" {
+		t.Fatalf("Unexpected validation errors %+v", verrs)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/mbeale/gorecurly
+
+go 1.23
@@ -3,13 +3,11 @@
 
 package gorecurly
 
-//TODO: Do all tests, do with mock server, need to fix URL so that it can be overriden
+//TODO: Do all tests, do with mock server
 //TODO: Check all comments when finished 
 //TODO: Change all paging to new request params 
 //TODO: Check that state is working with lists
 //TODO: Introduce stubs for all resources
-//TODO: PDF Invoice
-//TODO: Recurly.js signing
 //TODO: transparent post (probably not)
 //TODO: Double check fields and make sure no new fields were added
 //TODO: Option to add no auth to header "Recurly-Skip-Authorization: true"
@@ -18,25 +16,27 @@ package gorecurly
 //TODO: Custom function to calculate account balance
 //TODO: Custom function to calculate next billing amt
 //TODO: Discount in cents for coupons not working
-//TODO: Handle push notifications
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	URL               = "https://api.recurly.com/v2/"
-	libversion        = "0.4"
+	libversion        = "1.0"
 	libname           = "Recurly-Go"
 	ACCOUNTS          = "accounts"
 	ADJUSTMENTS       = "adjustments"
@@ -50,20 +50,12 @@ const (
 	TRANSACTIONS      = "transactions"
 )
 
-//Generic Reader
-type nopCloser struct {
-	io.Reader
-}
-
 //functions
 
-//Initialize the Recurly package with your apikey and your jskey
+//Initialize the Recurly package with your apikey and your jskey.
+//Use NewClient to configure the http client, url or timeout.
 func InitRecurly(apikey string, jskey string) *Recurly {
-	r := new(Recurly)
-	r.apiKey = apikey
-	r.JSKey = jskey
-	r.url = URL
-	return r
+	return NewClient(apikey, WithJSKey(jskey))
 }
 
 //interfaces
@@ -73,121 +65,41 @@ type Pager interface {
 	getRawBody() []byte
 }
 
-//Recurly errors
-var Error400 = errors.New("The request was invalid or could not be understood by the server. Resubmitting the request will likely result in the same error.")
-var Error401 = errors.New("Your API key is missing or invalid.")
-var Error402 = errors.New("Your Recurly account is in production mode but is not in good standing. Please pay any outstanding invoices.")
-var Error403 = errors.New("The login is attempting to perform an action it does not have privileges to access. Verify your login credentials are for the appropriate account.")
-var Error404 = errors.New("The resource was not found with the given identifier. The response body will explain which resource was not found.")
-var Error405 = errors.New("The requested method is not valid at the given URL.")
-var Error406 = errors.New("The request's Accept header is not set to application/xml")
-var Error412 = errors.New("The request was unsuccessful because a condition was not met. For example, this message may be returned if you attempt to cancel a subscription for an account that has no subscription.")
-var Error429 = errors.New("You have made too many API requests in the last hour. Future API requests will be ignored until the beginning of the next hour.")
-
-//Recurly Generic Errors
-type RecurlyError struct {
-	XMLName     xml.Name `xml:"error"`
-	statusCode  int
-	Symbol      string `xml:"symbol"`
-	Description string `xml:"description"`
-	Details     string `xml:"details"`
-}
-
-//Recurly Validation Errors Array
-type RecurlyValidationErrors struct {
-	XMLName    xml.Name `xml:"errors"`
-	statusCode int
-	Errors     []RecurlyValidationError `xml:"error"`
-}
-
-//Recurly validation error
-type RecurlyValidationError struct {
-	XMLName     xml.Name `xml:"error"`
-	FieldName   string   `xml:"field,attr"`
-	Symbol      string   `xml:"symbol,attr"`
-	Description string   `xml:",innerxml"`
-}
-
-//Parse Recurly XML to create a Recurly Error
-func CreateRecurlyStandardError(resp *http.Response) (r RecurlyError) {
-	r.statusCode = resp.StatusCode
-	if xmlstring, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-		if xmlerr := xml.Unmarshal(xmlstring, &r); xmlerr != nil {
-			r.Description = string(xmlstring)
-		}
-	}
-	return r
-}
-
-//Parse Recurly XML to create a Validation Error
-func CreateRecurlyValidationError(resp *http.Response) (r RecurlyValidationErrors) {
-	r.statusCode = resp.StatusCode
-	if xmlstring, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-		if xmlerr := xml.Unmarshal(xmlstring, &r); xmlerr != nil {
-			//r.Description = xmlerr.Error()
-			println(xmlerr.Error())
-		}
-	}
-	return r
-}
-
-//Filter to decide which error type to create
-func createRecurlyError(resp *http.Response) error {
-	switch resp.StatusCode {
-	case 400:
-		return Error400
-	case 401:
-		return Error401
-	case 402:
-		return Error402
-	case 403:
-		return Error403
-	case 404:
-		return Error404
-	case 405:
-		return Error405
-	case 406:
-		return Error406
-	case 412:
-		return Error412
-	case 429:
-		return Error429
-	case 422:
-		return CreateRecurlyValidationError(resp)
-	}
-	return CreateRecurlyStandardError(resp)
-}
-
-//Formatted General Error 
-func (r RecurlyError) Error() string {
-	return fmt.Sprintf("Recurly Error: %s , %s %s Status Code: %v", r.Symbol, r.Description, r.Details, r.statusCode)
-}
-
-//Formatted Validation Error
-func (r RecurlyValidationErrors) Error() string {
-	var rtnString string
-	for _, v := range r.Errors {
-		rtnString += v.FieldName + " " + v.Description + "\n"
-	}
-	return fmt.Sprintf("You have the following validation errors:\n%s", rtnString)
-}
-
 //Main Recurly Client
 type Recurly struct {
 	apiKey, JSKey, url string
 	debug              bool
-}
-
-//Set verbose debugging
+	client             *http.Client
+	timeout            time.Duration
+	retry              RetryPolicy
+	limiter            Limiter
+	logger             Logger
+	redactor           *redactor
+	middleware         []Middleware
+	tracer             Tracer
+	mu                 sync.Mutex
+	ratelimit          RateLimit
+}
+
+//Set verbose debugging, request and response bodies are logged at debug level.
+//Without a logger set by WithLogger the output goes to stdout.
 func (r *Recurly) EnableDebug() {
 	r.debug = true
+	if r.logger == nil {
+		r.logger = newDebugLogger()
+	}
 }
 
 //Get a list of accounts
 func (r *Recurly) GetAccounts(params ...url.Values) (AccountList, error) {
+	return r.GetAccountsCtx(context.Background(), params...)
+}
+
+//Same as GetAccounts, bound to a context
+func (r *Recurly) GetAccountsCtx(ctx context.Context, params ...url.Values) (AccountList, error) {
 	accountlist := AccountList{}
 	sendvars := accountlist.initParams(params)
-	if err := accountlist.initList(ACCOUNTS, sendvars, r); err == nil {
+	if err := accountlist.initList(ctx, ACCOUNTS, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(accountlist.getRawBody(), &accountlist); xmlerr == nil {
 			for k, _ := range accountlist.Account {
 				accountlist.Account[k].r = r
@@ -196,9 +108,7 @@ func (r *Recurly) GetAccounts(params ...url.Values) (AccountList, error) {
 			accountlist.r = r
 			return accountlist, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return accountlist, xmlerr
 		}
 	} else {
@@ -209,9 +119,14 @@ func (r *Recurly) GetAccounts(params ...url.Values) (AccountList, error) {
 
 //Get a list of adjustments for an account_code
 func (r *Recurly) GetAdjustments(account_code string, params ...url.Values) (AdjustmentList, error) {
+	return r.GetAdjustmentsCtx(context.Background(), account_code, params...)
+}
+
+//Same as GetAdjustments, bound to a context
+func (r *Recurly) GetAdjustmentsCtx(ctx context.Context, account_code string, params ...url.Values) (AdjustmentList, error) {
 	adjlist := AdjustmentList{}
 	sendvars := adjlist.initParams(params)
-	if err := adjlist.initList(ACCOUNTS+"/"+account_code+"/"+ADJUSTMENTS, sendvars, r); err == nil {
+	if err := adjlist.initList(ctx, ACCOUNTS+"/"+account_code+"/"+ADJUSTMENTS, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(adjlist.getRawBody(), &adjlist); xmlerr == nil {
 			for k, _ := range adjlist.Adjustments {
 				adjlist.Adjustments[k].r = r
@@ -221,9 +136,7 @@ func (r *Recurly) GetAdjustments(account_code string, params ...url.Values) (Adj
 			adjlist.AccountCode = account_code
 			return adjlist, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return adjlist, xmlerr
 		}
 	} else {
@@ -234,9 +147,14 @@ func (r *Recurly) GetAdjustments(account_code string, params ...url.Values) (Adj
 
 //Get a list of coupons
 func (r *Recurly) GetCoupons(params ...url.Values) (CouponList, error) {
+	return r.GetCouponsCtx(context.Background(), params...)
+}
+
+//Same as GetCoupons, bound to a context
+func (r *Recurly) GetCouponsCtx(ctx context.Context, params ...url.Values) (CouponList, error) {
 	cplist := CouponList{}
 	sendvars := cplist.initParams(params)
-	if err := cplist.initList(COUPONS, sendvars, r); err == nil {
+	if err := cplist.initList(ctx, COUPONS, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(cplist.getRawBody(), &cplist); xmlerr == nil {
 			for k, _ := range cplist.Coupons {
 				cplist.Coupons[k].r = r
@@ -245,9 +163,7 @@ func (r *Recurly) GetCoupons(params ...url.Values) (CouponList, error) {
 			cplist.r = r
 			return cplist, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return cplist, xmlerr
 		}
 	} else {
@@ -258,9 +174,14 @@ func (r *Recurly) GetCoupons(params ...url.Values) (CouponList, error) {
 
 //Get a list of invoices for an account_code
 func (r *Recurly) GetAccountInvoices(account_code string, params ...url.Values) (AccountInvoiceList, error) {
+	return r.GetAccountInvoicesCtx(context.Background(), account_code, params...)
+}
+
+//Same as GetAccountInvoices, bound to a context
+func (r *Recurly) GetAccountInvoicesCtx(ctx context.Context, account_code string, params ...url.Values) (AccountInvoiceList, error) {
 	invoicelist := AccountInvoiceList{}
 	sendvars := invoicelist.initParams(params)
-	if err := invoicelist.initList(ACCOUNTS+"/"+account_code+"/"+INVOICES, sendvars, r); err == nil {
+	if err := invoicelist.initList(ctx, ACCOUNTS+"/"+account_code+"/"+INVOICES, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(invoicelist.getRawBody(), &invoicelist); xmlerr == nil {
 			for k, _ := range invoicelist.Invoices {
 				invoicelist.Invoices[k].r = r
@@ -270,9 +191,7 @@ func (r *Recurly) GetAccountInvoices(account_code string, params ...url.Values)
 			invoicelist.AccountCode = account_code
 			return invoicelist, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return invoicelist, xmlerr
 		}
 	} else {
@@ -283,9 +202,14 @@ func (r *Recurly) GetAccountInvoices(account_code string, params ...url.Values)
 
 //Get a list of invoices
 func (r *Recurly) GetInvoices(params ...url.Values) (InvoiceList, error) {
+	return r.GetInvoicesCtx(context.Background(), params...)
+}
+
+//Same as GetInvoices, bound to a context
+func (r *Recurly) GetInvoicesCtx(ctx context.Context, params ...url.Values) (InvoiceList, error) {
 	invoicelist := InvoiceList{}
 	sendvars := invoicelist.initParams(params)
-	if err := invoicelist.initList(INVOICES, sendvars, r); err == nil {
+	if err := invoicelist.initList(ctx, INVOICES, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(invoicelist.getRawBody(), &invoicelist); xmlerr == nil {
 			for k, _ := range invoicelist.Invoices {
 				invoicelist.Invoices[k].r = r
@@ -294,9 +218,7 @@ func (r *Recurly) GetInvoices(params ...url.Values) (InvoiceList, error) {
 			invoicelist.r = r
 			return invoicelist, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return invoicelist, xmlerr
 		}
 	} else {
@@ -307,9 +229,14 @@ func (r *Recurly) GetInvoices(params ...url.Values) (InvoiceList, error) {
 
 //Get a list of Plans
 func (r *Recurly) GetPlans(params ...url.Values) (PlanList, error) {
+	return r.GetPlansCtx(context.Background(), params...)
+}
+
+//Same as GetPlans, bound to a context
+func (r *Recurly) GetPlansCtx(ctx context.Context, params ...url.Values) (PlanList, error) {
 	planlist := PlanList{}
 	sendvars := planlist.initParams(params)
-	if err := planlist.initList(PLANS, sendvars, r); err == nil {
+	if err := planlist.initList(ctx, PLANS, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(planlist.getRawBody(), &planlist); xmlerr == nil {
 			for k, _ := range planlist.Plans {
 				planlist.Plans[k].r = r
@@ -318,9 +245,7 @@ func (r *Recurly) GetPlans(params ...url.Values) (PlanList, error) {
 			planlist.r = r
 			return planlist, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return planlist, xmlerr
 		}
 	} else {
@@ -331,8 +256,13 @@ func (r *Recurly) GetPlans(params ...url.Values) (PlanList, error) {
 
 //Get a list of add ons for a plan_code
 func (r *Recurly) GetPlanAddOns(plan_code string, params ...url.Values) (planaddonlist PlanAddOnList, e error) {
+	return r.GetPlanAddOnsCtx(context.Background(), plan_code, params...)
+}
+
+//Same as GetPlanAddOns, bound to a context
+func (r *Recurly) GetPlanAddOnsCtx(ctx context.Context, plan_code string, params ...url.Values) (planaddonlist PlanAddOnList, e error) {
 	sendvars := planaddonlist.initParams(params)
-	if err := planaddonlist.initList(PLANS+"/"+plan_code+"/add_ons", sendvars, r); err == nil {
+	if err := planaddonlist.initList(ctx, PLANS+"/"+plan_code+"/add_ons", sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(planaddonlist.getRawBody(), &planaddonlist); xmlerr == nil {
 			for k, _ := range planaddonlist.AddOns {
 				planaddonlist.AddOns[k].r = r
@@ -341,9 +271,7 @@ func (r *Recurly) GetPlanAddOns(plan_code string, params ...url.Values) (planadd
 			planaddonlist.PlanCode = plan_code
 			return
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return planaddonlist, xmlerr
 		}
 	} else {
@@ -354,9 +282,14 @@ func (r *Recurly) GetPlanAddOns(plan_code string, params ...url.Values) (planadd
 
 //Get a list of subscriptions
 func (r *Recurly) GetSubscriptions(params ...url.Values) (SubscriptionList, error) {
+	return r.GetSubscriptionsCtx(context.Background(), params...)
+}
+
+//Same as GetSubscriptions, bound to a context
+func (r *Recurly) GetSubscriptionsCtx(ctx context.Context, params ...url.Values) (SubscriptionList, error) {
 	subs := SubscriptionList{}
 	sendvars := subs.initParams(params)
-	if err := subs.initList(SUBSCRIPTIONS, sendvars, r); err == nil {
+	if err := subs.initList(ctx, SUBSCRIPTIONS, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(subs.getRawBody(), &subs); xmlerr == nil {
 			for k, _ := range subs.Subscriptions {
 				subs.Subscriptions[k].r = r
@@ -365,9 +298,7 @@ func (r *Recurly) GetSubscriptions(params ...url.Values) (SubscriptionList, erro
 			subs.r = r
 			return subs, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return subs, xmlerr
 		}
 	} else {
@@ -378,9 +309,14 @@ func (r *Recurly) GetSubscriptions(params ...url.Values) (SubscriptionList, erro
 
 //Get a list of subscriptions for an account_code
 func (r *Recurly) GetAccountSubscriptions(account_code string, params ...url.Values) (AccountSubscriptionList, error) {
+	return r.GetAccountSubscriptionsCtx(context.Background(), account_code, params...)
+}
+
+//Same as GetAccountSubscriptions, bound to a context
+func (r *Recurly) GetAccountSubscriptionsCtx(ctx context.Context, account_code string, params ...url.Values) (AccountSubscriptionList, error) {
 	subs := AccountSubscriptionList{}
 	sendvars := subs.initParams(params)
-	if err := subs.initList(ACCOUNTS+"/"+account_code+"/"+SUBSCRIPTIONS, sendvars, r); err == nil {
+	if err := subs.initList(ctx, ACCOUNTS+"/"+account_code+"/"+SUBSCRIPTIONS, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(subs.getRawBody(), &subs); xmlerr == nil {
 			for k, _ := range subs.Subscriptions {
 				subs.Subscriptions[k].r = r
@@ -390,9 +326,7 @@ func (r *Recurly) GetAccountSubscriptions(account_code string, params ...url.Val
 			subs.AccountCode = account_code
 			return subs, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return subs, xmlerr
 		}
 	} else {
@@ -403,9 +337,14 @@ func (r *Recurly) GetAccountSubscriptions(account_code string, params ...url.Val
 
 //Get a list of transactions
 func (r *Recurly) GetTransactions(params ...url.Values) (TransactionList, error) {
+	return r.GetTransactionsCtx(context.Background(), params...)
+}
+
+//Same as GetTransactions, bound to a context
+func (r *Recurly) GetTransactionsCtx(ctx context.Context, params ...url.Values) (TransactionList, error) {
 	subs := TransactionList{}
 	sendvars := subs.initParams(params)
-	if err := subs.initList(TRANSACTIONS, sendvars, r); err == nil {
+	if err := subs.initList(ctx, TRANSACTIONS, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(subs.getRawBody(), &subs); xmlerr == nil {
 			for k, _ := range subs.Transactions {
 				subs.Transactions[k].r = r
@@ -414,9 +353,7 @@ func (r *Recurly) GetTransactions(params ...url.Values) (TransactionList, error)
 			subs.r = r
 			return subs, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return subs, xmlerr
 		}
 	} else {
@@ -427,9 +364,14 @@ func (r *Recurly) GetTransactions(params ...url.Values) (TransactionList, error)
 
 //Get a list of transactions for an account_code
 func (r *Recurly) GetAccountTransactions(account_code string, params ...url.Values) (AccountTransactionList, error) {
+	return r.GetAccountTransactionsCtx(context.Background(), account_code, params...)
+}
+
+//Same as GetAccountTransactions, bound to a context
+func (r *Recurly) GetAccountTransactionsCtx(ctx context.Context, account_code string, params ...url.Values) (AccountTransactionList, error) {
 	subs := AccountTransactionList{}
 	sendvars := subs.initParams(params)
-	if err := subs.initList(ACCOUNTS+"/"+account_code+"/"+TRANSACTIONS, sendvars, r); err == nil {
+	if err := subs.initList(ctx, ACCOUNTS+"/"+account_code+"/"+TRANSACTIONS, sendvars, r); err == nil {
 		if xmlerr := xml.Unmarshal(subs.getRawBody(), &subs); xmlerr == nil {
 			for k, _ := range subs.Transactions {
 				subs.Transactions[k].r = r
@@ -439,9 +381,7 @@ func (r *Recurly) GetAccountTransactions(account_code string, params ...url.Valu
 			subs.AccountCode = account_code
 			return subs, nil
 		} else {
-			if r.debug {
-				println(xmlerr.Error())
-			}
+			r.log(ctx, slog.LevelError, "recurly: could not decode list", slog.Any("error", xmlerr))
 			return subs, xmlerr
 		}
 	} else {
@@ -450,20 +390,74 @@ func (r *Recurly) GetAccountTransactions(account_code string, params ...url.Valu
 	return subs, nil
 }
 
+//Count the accounts matching opts without fetching a page
+func (r *Recurly) CountAccounts(opts AccountListOptions) (int, error) {
+	return r.CountAccountsCtx(context.Background(), opts)
+}
+
+//Same as CountAccounts, bound to a context
+func (r *Recurly) CountAccountsCtx(ctx context.Context, opts AccountListOptions) (int, error) {
+	params, err := opts.Values()
+	if err != nil {
+		return 0, err
+	}
+	return r.count(ctx, ACCOUNTS, params)
+}
+
+//Count the subscriptions matching opts without fetching a page
+func (r *Recurly) CountSubscriptions(opts SubscriptionListOptions) (int, error) {
+	return r.CountSubscriptionsCtx(context.Background(), opts)
+}
+
+//Same as CountSubscriptions, bound to a context
+func (r *Recurly) CountSubscriptionsCtx(ctx context.Context, opts SubscriptionListOptions) (int, error) {
+	params, err := opts.Values()
+	if err != nil {
+		return 0, err
+	}
+	return r.count(ctx, SUBSCRIPTIONS, params)
+}
+
+//Count the transactions matching opts without fetching a page
+func (r *Recurly) CountTransactions(opts TransactionListOptions) (int, error) {
+	return r.CountTransactionsCtx(context.Background(), opts)
+}
+
+//Same as CountTransactions, bound to a context
+func (r *Recurly) CountTransactionsCtx(ctx context.Context, opts TransactionListOptions) (int, error) {
+	params, err := opts.Values()
+	if err != nil {
+		return 0, err
+	}
+	return r.count(ctx, TRANSACTIONS, params)
+}
+
+//Count the invoices matching opts without fetching a page
+func (r *Recurly) CountInvoices(opts InvoiceListOptions) (int, error) {
+	return r.CountInvoicesCtx(context.Background(), opts)
+}
+
+//Same as CountInvoices, bound to a context
+func (r *Recurly) CountInvoicesCtx(ctx context.Context, opts InvoiceListOptions) (int, error) {
+	params, err := opts.Values()
+	if err != nil {
+		return 0, err
+	}
+	return r.count(ctx, INVOICES, params)
+}
+
 //Get a single account by account_code
 func (r *Recurly) GetAccount(account_code string) (account Account, err error) {
+	return r.GetAccountCtx(context.Background(), account_code)
+}
+
+//Same as GetAccount, bound to a context
+func (r *Recurly) GetAccountCtx(ctx context.Context, account_code string) (account Account, err error) {
 	account = r.NewAccount()
-	if resp, err := r.createRequest(ACCOUNTS+"/"+account_code, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, ACCOUNTS+"/"+account_code, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &account); xmlerr != nil {
 					account.B = nil
@@ -477,7 +471,7 @@ func (r *Recurly) GetAccount(account_code string) (account Account, err error) {
 			}
 			return account, nil
 		} else {
-			return account, createRecurlyError(resp)
+			return account, r.createRecurlyError(resp)
 		}
 	} else {
 		return account, err
@@ -487,18 +481,16 @@ func (r *Recurly) GetAccount(account_code string) (account Account, err error) {
 
 //Get a single adjustment by uuid
 func (r *Recurly) GetAdjustment(uuid string) (adj Adjustment, err error) {
+	return r.GetAdjustmentCtx(context.Background(), uuid)
+}
+
+//Same as GetAdjustment, bound to a context
+func (r *Recurly) GetAdjustmentCtx(ctx context.Context, uuid string) (adj Adjustment, err error) {
 	adj = r.NewAdjustment()
-	if resp, err := r.createRequest(ADJUSTMENTS+"/"+uuid, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, ADJUSTMENTS+"/"+uuid, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &adj); xmlerr != nil {
 					return adj, xmlerr
@@ -511,7 +503,7 @@ func (r *Recurly) GetAdjustment(uuid string) (adj Adjustment, err error) {
 			}
 			return adj, nil
 		} else {
-			return adj, createRecurlyError(resp)
+			return adj, r.createRecurlyError(resp)
 		}
 	} else {
 		return adj, err
@@ -521,19 +513,17 @@ func (r *Recurly) GetAdjustment(uuid string) (adj Adjustment, err error) {
 
 //Get a single coupon redemption by account_code
 func (r *Recurly) GetCouponRedemption(account_code string) (red Redemption, err error) {
+	return r.GetCouponRedemptionCtx(context.Background(), account_code)
+}
+
+//Same as GetCouponRedemption, bound to a context
+func (r *Recurly) GetCouponRedemptionCtx(ctx context.Context, account_code string) (red Redemption, err error) {
 	red.r = r
 	red.AccountCode = account_code
-	if resp, err := r.createRequest(ACCOUNTS+"/"+account_code+"/redemption", "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, ACCOUNTS+"/"+account_code+"/redemption", "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &red); xmlerr != nil {
 					return red, xmlerr
@@ -546,7 +536,7 @@ func (r *Recurly) GetCouponRedemption(account_code string) (red Redemption, err
 			}
 			return red, nil
 		} else {
-			return red, createRecurlyError(resp)
+			return red, r.createRecurlyError(resp)
 		}
 	} else {
 		return red, err
@@ -556,18 +546,16 @@ func (r *Recurly) GetCouponRedemption(account_code string) (red Redemption, err
 
 //Get a single coupon by uuid
 func (r *Recurly) GetCoupon(uuid string) (coupon Coupon, err error) {
+	return r.GetCouponCtx(context.Background(), uuid)
+}
+
+//Same as GetCoupon, bound to a context
+func (r *Recurly) GetCouponCtx(ctx context.Context, uuid string) (coupon Coupon, err error) {
 	coupon = r.NewCoupon()
-	if resp, err := r.createRequest(COUPONS+"/"+uuid, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, COUPONS+"/"+uuid, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &coupon); xmlerr != nil {
 					return coupon, xmlerr
@@ -580,7 +568,7 @@ func (r *Recurly) GetCoupon(uuid string) (coupon Coupon, err error) {
 			}
 			return coupon, nil
 		} else {
-			return coupon, createRecurlyError(resp)
+			return coupon, r.createRecurlyError(resp)
 		}
 	} else {
 		return coupon, err
@@ -590,22 +578,21 @@ func (r *Recurly) GetCoupon(uuid string) (coupon Coupon, err error) {
 
 //Get invoice by uuid
 func (r *Recurly) GetInvoice(uuid string) (invoice Invoice, err error) {
+	return r.GetInvoiceCtx(context.Background(), uuid)
+}
+
+//Same as GetInvoice, bound to a context
+func (r *Recurly) GetInvoiceCtx(ctx context.Context, uuid string) (invoice Invoice, err error) {
 	invoice = r.NewInvoice()
-	if resp, err := r.createRequest(INVOICES+"/"+uuid, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, INVOICES+"/"+uuid, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &invoice); xmlerr != nil {
 					return invoice, xmlerr
 				}
+				invoice.attach(r)
 				//everything went fine
 				return invoice, nil
 			} else {
@@ -614,7 +601,7 @@ func (r *Recurly) GetInvoice(uuid string) (invoice Invoice, err error) {
 			}
 			return invoice, nil
 		} else {
-			return invoice, createRecurlyError(resp)
+			return invoice, r.createRecurlyError(resp)
 		}
 	} else {
 		return invoice, err
@@ -622,20 +609,40 @@ func (r *Recurly) GetInvoice(uuid string) (invoice Invoice, err error) {
 	return invoice, nil
 }
 
+//Download the pdf of an invoice in lang, e.g. "de-DE", an empty lang is en-US.
+//The body is streamed from Recurly and must be closed.
+func (r *Recurly) GetInvoicePDF(invoice_number, lang string) (io.ReadCloser, error) {
+	return r.GetInvoicePDFCtx(context.Background(), invoice_number, lang)
+}
+
+//Same as GetInvoicePDF, bound to a context
+func (r *Recurly) GetInvoicePDFCtx(ctx context.Context, invoice_number, lang string) (io.ReadCloser, error) {
+	if invoice_number == "" {
+		return nil, errors.New("Not a valid invoice")
+	}
+	ctx = withAccept(withOperation(ctx, "pdf"), "application/pdf", lang)
+	resp, err := r.createRequest(ctx, INVOICES+"/"+invoice_number, "GET", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, r.createRecurlyError(resp)
+	}
+	return resp.Body, nil
+}
+
 //Get a single plan by plan_code
 func (r *Recurly) GetPlan(plan_code string) (plan Plan, err error) {
+	return r.GetPlanCtx(context.Background(), plan_code)
+}
+
+//Same as GetPlan, bound to a context
+func (r *Recurly) GetPlanCtx(ctx context.Context, plan_code string) (plan Plan, err error) {
 	plan = r.NewPlan()
-	if resp, err := r.createRequest(PLANS+"/"+plan_code, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, PLANS+"/"+plan_code, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &plan); xmlerr != nil {
 					return plan, xmlerr
@@ -648,7 +655,7 @@ func (r *Recurly) GetPlan(plan_code string) (plan Plan, err error) {
 			}
 			return plan, nil
 		} else {
-			return plan, createRecurlyError(resp)
+			return plan, r.createRecurlyError(resp)
 		}
 	} else {
 		return plan, err
@@ -658,18 +665,16 @@ func (r *Recurly) GetPlan(plan_code string) (plan Plan, err error) {
 
 //Get a single plan add on by plan_code and add_on_code
 func (r *Recurly) GetPlanAddOn(plan_code, add_on_code string) (plan PlanAddOn, err error) {
+	return r.GetPlanAddOnCtx(context.Background(), plan_code, add_on_code)
+}
+
+//Same as GetPlanAddOn, bound to a context
+func (r *Recurly) GetPlanAddOnCtx(ctx context.Context, plan_code, add_on_code string) (plan PlanAddOn, err error) {
 	plan = r.NewPlanAddOn()
-	if resp, err := r.createRequest(PLANS+"/"+plan_code+"/add_ons/"+add_on_code, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, PLANS+"/"+plan_code+"/add_ons/"+add_on_code, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &plan); xmlerr != nil {
 					return plan, xmlerr
@@ -682,7 +687,7 @@ func (r *Recurly) GetPlanAddOn(plan_code, add_on_code string) (plan PlanAddOn, e
 			}
 			return plan, nil
 		} else {
-			return plan, createRecurlyError(resp)
+			return plan, r.createRecurlyError(resp)
 		}
 	} else {
 		return plan, err
@@ -692,18 +697,16 @@ func (r *Recurly) GetPlanAddOn(plan_code, add_on_code string) (plan PlanAddOn, e
 
 //Get a single subscription by uuid
 func (r *Recurly) GetSubscription(uuid string) (sub Subscription, err error) {
+	return r.GetSubscriptionCtx(context.Background(), uuid)
+}
+
+//Same as GetSubscription, bound to a context
+func (r *Recurly) GetSubscriptionCtx(ctx context.Context, uuid string) (sub Subscription, err error) {
 	sub = r.NewSubscription()
-	if resp, err := r.createRequest(SUBSCRIPTIONS+"/"+uuid, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, SUBSCRIPTIONS+"/"+uuid, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &sub); xmlerr != nil {
 					return sub, xmlerr
@@ -716,7 +719,7 @@ func (r *Recurly) GetSubscription(uuid string) (sub Subscription, err error) {
 			}
 			return sub, nil
 		} else {
-			return sub, createRecurlyError(resp)
+			return sub, r.createRecurlyError(resp)
 		}
 	} else {
 		return sub, err
@@ -726,18 +729,16 @@ func (r *Recurly) GetSubscription(uuid string) (sub Subscription, err error) {
 
 //Get a single transaction by uuid
 func (r *Recurly) GetTransaction(uuid string) (tran Transaction, err error) {
+	return r.GetTransactionCtx(context.Background(), uuid)
+}
+
+//Same as GetTransaction, bound to a context
+func (r *Recurly) GetTransactionCtx(ctx context.Context, uuid string) (tran Transaction, err error) {
 	tran = r.NewTransaction()
-	if resp, err := r.createRequest(TRANSACTIONS+"/"+uuid, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, TRANSACTIONS+"/"+uuid, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &tran); xmlerr != nil {
 					return tran, xmlerr
@@ -750,7 +751,7 @@ func (r *Recurly) GetTransaction(uuid string) (tran Transaction, err error) {
 			}
 			return tran, nil
 		} else {
-			return tran, createRecurlyError(resp)
+			return tran, r.createRecurlyError(resp)
 		}
 	} else {
 		return tran, err
@@ -818,8 +819,13 @@ func (r *Recurly) NewTransaction() (transaction Transaction) {
 
 //Invoice Pending Charges on an account
 func (r *Recurly) InvoicePendingCharges(account_code string) (invoice Invoice, e error) {
+	return r.InvoicePendingChargesCtx(context.Background(), account_code)
+}
+
+//Same as InvoicePendingCharges, bound to a context
+func (r *Recurly) InvoicePendingChargesCtx(ctx context.Context, account_code string) (invoice Invoice, e error) {
 	invoice.r = r
-	e = invoice.r.doCreate(&invoice, ACCOUNTS+"/"+account_code+"/invoices")
+	e = invoice.r.doCreate(ctx, &invoice, ACCOUNTS+"/"+account_code+"/invoices")
 	return
 }
 
@@ -832,23 +838,22 @@ func (r *Recurly) NewInvoice() (invoice Invoice) {
 
 //Get a single accounts billing info by account_code
 func (r *Recurly) GetBillingInfo(account_code string) (bi BillingInfo, err error) {
+	return r.GetBillingInfoCtx(context.Background(), account_code)
+}
+
+//Same as GetBillingInfo, bound to a context
+func (r *Recurly) GetBillingInfoCtx(ctx context.Context, account_code string) (bi BillingInfo, err error) {
 	bi = r.NewBillingInfo()
-	if resp, err := r.createRequest(ACCOUNTS+"/"+account_code+"/"+BILLINGINFO, "GET", nil, nil); err == nil {
+	if resp, err := r.createRequest(ctx, ACCOUNTS+"/"+account_code+"/"+BILLINGINFO, "GET", nil, nil); err == nil {
 		if resp.StatusCode == 200 {
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
+				r.logResponseBody(ctx, resp, body)
 				//load object xml
 				if xmlerr := xml.Unmarshal(body, &bi); xmlerr != nil {
 					return bi, xmlerr
 				}
 				//everything went fine
+				bi.markLoaded()
 				bi.Account.endpoint = ACCOUNTS
 				return bi, nil
 			} else {
@@ -857,7 +862,7 @@ func (r *Recurly) GetBillingInfo(account_code string) (bi BillingInfo, err error
 			}
 			return bi, nil
 		} else {
-			return bi, createRecurlyError(resp)
+			return bi, r.createRecurlyError(resp)
 		}
 	} else {
 		return bi, err
@@ -865,54 +870,96 @@ func (r *Recurly) GetBillingInfo(account_code string) (bi BillingInfo, err error
 	return bi, nil
 }
 
-//Create a request to Recurly and return that response object
-func (r *Recurly) createRequest(endpoint string, method string, params url.Values, msgbody []byte) (*http.Response, error) {
-	client := &http.Client{}
+//Create a request to Recurly and return that response object.
+//Failed attempts are retried according to the client's RetryPolicy.
+func (r *Recurly) createRequest(ctx context.Context, endpoint string, method string, params url.Values, msgbody []byte) (_ *http.Response, e error) {
+	ctx, endSpan := r.startSpan(ctx, method, endpoint)
+	defer func() { endSpan(e) }()
 	u, err := url.Parse(r.url + endpoint)
 	if err != nil {
 		return nil, err
 	}
 	u.RawQuery = u.RawQuery + params.Encode()
-	body := nopCloser{bytes.NewBufferString(string(msgbody))}
-	if r.debug {
-		fmt.Printf("Endpoint Requested: %s Method: %s Body: %s\n", u.String(), method, string(msgbody))
+	r.logRequestBody(ctx, method, u.String(), msgbody)
+	info := newRequestInfo(ctx, method, endpoint)
+	for attempt := 0; ; attempt++ {
+		if r.limiter != nil {
+			if err := r.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		start := time.Now()
+		info.Attempt = attempt
+		resp, resperr := r.sendRequest(context.WithValue(ctx, requestInfoKey{}, info), method, u.String(), msgbody)
+		r.logAttempt(ctx, method, endpoint, attempt, start, resp, resperr)
+		if resperr == nil {
+			r.recordRateLimit(resp.Header)
+			setSpanResponse(ctx, resp.StatusCode, resp.Header.Get("X-Request-Id"))
+		}
+		wait, retry := r.retry.backoff(method, attempt, resp, resperr)
+		if !retry {
+			return resp, resperr
+		}
+		if resp != nil {
+			closeBody(resp)
+		}
+		r.log(ctx, slog.LevelInfo, "recurly retrying request", slog.String("method", method), slog.String("endpoint", endpoint), slog.Duration("wait", wait))
+		if err := sleepCtx(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
-	if req, err := http.NewRequest(method, u.String(), body); err != nil {
+}
+
+//Drain and close a response body so its connection goes back to the pool
+func closeBody(resp *http.Response) {
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+//Send a single attempt of a request to Recurly
+func (r *Recurly) sendRequest(ctx context.Context, method string, u string, msgbody []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(msgbody))
+	if err != nil {
 		return nil, err
-	} else {
-		req.Header.Add("Accept", "application/xml")
-		req.Header.Add("Accept-Language", "en-US")
-		req.Header.Add("User-Agent", libname+" version="+libversion)
-		req.Header.Add("Content-Type", "application/xml; charset=utf-8")
-		req.ContentLength = int64(len(string(msgbody)))
-		req.SetBasicAuth(r.apiKey, "")
-		if resp, resperr := client.Do(req); resperr == nil {
-			return resp, nil
-		} else {
-			return nil, resperr
-		}
 	}
-	return nil, nil
+	a := accept{"application/xml", "en-US"}
+	if v, ok := ctx.Value(acceptKey{}).(accept); ok {
+		a = v
+	}
+	req.Header.Add("Accept", a.mediaType)
+	req.Header.Add("Accept-Language", a.language)
+	req.Header.Add("User-Agent", libname+" version="+libversion)
+	req.Header.Add("Content-Type", "application/xml; charset=utf-8")
+	req.SetBasicAuth(r.apiKey, "")
+	return r.roundTrip()(req)
+}
+
+type acceptKey struct{}
+
+//The media type and language requested from Recurly
+type accept struct {
+	mediaType, language string
+}
+
+//Request mediaType in language instead of xml in en-US for the requests made with ctx
+func withAccept(ctx context.Context, mediaType, language string) context.Context {
+	if language == "" {
+		language = "en-US"
+	}
+	return context.WithValue(ctx, acceptKey{}, accept{mediaType, language})
 }
 
 //process create request and return the updated interface
-func (r *Recurly) doCreateReturn(v, ret interface{}, endpoint string) (e error) {
+func (r *Recurly) doCreateReturn(ctx context.Context, v, ret interface{}, endpoint string) (e error) {
+	ctx, endSpan := r.startSpan(ctx, "POST", endpoint)
+	defer func() { endSpan(e) }()
 	if xmlstring, err := xml.MarshalIndent(v, "", "    "); err == nil {
 		xmlstring = []byte(xml.Header + string(xmlstring))
-		if r.debug {
-			fmt.Printf("%s\n", xmlstring)
-		}
-		if resp, reqerr := r.createRequest(endpoint, "POST", nil, xmlstring); reqerr == nil {
+		if resp, reqerr := r.createRequest(ctx, endpoint, "POST", nil, xmlstring); reqerr == nil {
+			defer closeBody(resp)
 			if resp.StatusCode < 400 {
 				if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-					if r.debug {
-						println(resp.Status)
-						for k, _ := range resp.Header {
-							println(k + ":" + resp.Header[k][0])
-						}
-						fmt.Printf("%s\n", body)
-						fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-					}
+					r.logResponseBody(ctx, resp, body)
 					//load object xml
 					if xmlerr := xml.Unmarshal(body, ret); xmlerr != nil {
 						return xmlerr
@@ -925,7 +972,7 @@ func (r *Recurly) doCreateReturn(v, ret interface{}, endpoint string) (e error)
 				}
 				return nil
 			} else {
-				return createRecurlyError(resp)
+				return r.createRecurlyError(resp)
 			}
 		} else {
 			return reqerr
@@ -937,23 +984,16 @@ func (r *Recurly) doCreateReturn(v, ret interface{}, endpoint string) (e error)
 }
 
 //Create a resource from struct, uses POST method
-func (r *Recurly) doCreate(v interface{}, endpoint string) error {
+func (r *Recurly) doCreate(ctx context.Context, v interface{}, endpoint string) (e error) {
+	ctx, endSpan := r.startSpan(ctx, "POST", endpoint)
+	defer func() { endSpan(e) }()
 	if xmlstring, err := xml.MarshalIndent(v, "", "    "); err == nil {
 		xmlstring = []byte(xml.Header + string(xmlstring))
-		if r.debug {
-			fmt.Printf("%s\n", xmlstring)
-		}
-		if resp, reqerr := r.createRequest(endpoint, "POST", nil, xmlstring); reqerr == nil {
+		if resp, reqerr := r.createRequest(ctx, endpoint, "POST", nil, xmlstring); reqerr == nil {
+			defer closeBody(resp)
 			if resp.StatusCode < 400 {
 				if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-					if r.debug {
-						println(resp.Status)
-						for k, _ := range resp.Header {
-							println(k + ":" + resp.Header[k][0])
-						}
-						fmt.Printf("%s\n", body)
-						fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-					}
+					r.logResponseBody(ctx, resp, body)
 					//load object xml
 					if xmlerr := xml.Unmarshal(body, v); xmlerr != nil {
 						return xmlerr
@@ -966,7 +1006,7 @@ func (r *Recurly) doCreate(v interface{}, endpoint string) error {
 				}
 				return nil
 			} else {
-				return createRecurlyError(resp)
+				return r.createRecurlyError(resp)
 			}
 		} else {
 			return reqerr
@@ -978,25 +1018,18 @@ func (r *Recurly) doCreate(v interface{}, endpoint string) error {
 }
 
 //Update a resource from Struct, then return the updated object uses PUT method
-func (r *Recurly) doUpdateReturn(v, ret interface{}, endpoint string) error {
+func (r *Recurly) doUpdateReturn(ctx context.Context, v, ret interface{}, endpoint string) (e error) {
+	ctx, endSpan := r.startSpan(ctx, "PUT", endpoint)
+	defer func() { endSpan(e) }()
 	if xmlstring, err := xml.MarshalIndent(v, "", "    "); err == nil {
 		if v != nil {
 			xmlstring = []byte(xml.Header + string(xmlstring))
 		}
-		if r.debug {
-			fmt.Printf("%s\n", xmlstring)
-		}
-		if resp, reqerr := r.createRequest(endpoint, "PUT", nil, xmlstring); reqerr == nil {
+		if resp, reqerr := r.createRequest(ctx, endpoint, "PUT", nil, xmlstring); reqerr == nil {
+			defer closeBody(resp)
 			if resp.StatusCode < 400 {
 				if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-					if r.debug {
-						println(resp.Status)
-						for k, _ := range resp.Header {
-							println(k + ":" + resp.Header[k][0])
-						}
-						fmt.Printf("%s\n", body)
-						fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-					}
+					r.logResponseBody(ctx, resp, body)
 					//load object xml
 					if xmlerr := xml.Unmarshal(body, ret); xmlerr != nil {
 						return xmlerr
@@ -1009,7 +1042,7 @@ func (r *Recurly) doUpdateReturn(v, ret interface{}, endpoint string) error {
 				}
 				return nil
 			} else {
-				return createRecurlyError(resp)
+				return r.createRecurlyError(resp)
 			}
 		} else {
 			return reqerr
@@ -1021,17 +1054,17 @@ func (r *Recurly) doUpdateReturn(v, ret interface{}, endpoint string) error {
 }
 
 //Update a resource from Struct, uses PUT method
-func (r *Recurly) doUpdate(v interface{}, endpoint string) error {
+func (r *Recurly) doUpdate(ctx context.Context, v interface{}, endpoint string) (e error) {
+	ctx, endSpan := r.startSpan(ctx, "PUT", endpoint)
+	defer func() { endSpan(e) }()
 	if xmlstring, err := xml.MarshalIndent(v, "", "    "); err == nil {
 		xmlstring = []byte(xml.Header + string(xmlstring))
-		if r.debug {
-			fmt.Printf("%s\n", xmlstring)
-		}
-		if resp, reqerr := r.createRequest(endpoint, "PUT", nil, xmlstring); reqerr == nil {
+		if resp, reqerr := r.createRequest(ctx, endpoint, "PUT", nil, xmlstring); reqerr == nil {
+			defer closeBody(resp)
 			if resp.StatusCode < 400 {
 				return nil
 			} else {
-				return createRecurlyError(resp)
+				return r.createRecurlyError(resp)
 			}
 		} else {
 			return reqerr
@@ -1043,12 +1076,15 @@ func (r *Recurly) doUpdate(v interface{}, endpoint string) error {
 }
 
 //Delete a resource, uses DELETE method
-func (r *Recurly) doDelete(endpoint string) error {
-	if resp, reqerr := r.createRequest(endpoint, "DELETE", nil, nil); reqerr == nil {
+func (r *Recurly) doDelete(ctx context.Context, endpoint string) (e error) {
+	ctx, endSpan := r.startSpan(ctx, "DELETE", endpoint)
+	defer func() { endSpan(e) }()
+	if resp, reqerr := r.createRequest(ctx, endpoint, "DELETE", nil, nil); reqerr == nil {
+		defer closeBody(resp)
 		if resp.StatusCode < 400 {
 			return nil
 		} else {
-			return createRecurlyError(resp)
+			return r.createRecurlyError(resp)
 		}
 	} else {
 		return reqerr
@@ -1063,6 +1099,7 @@ type Paging struct {
 	rawBody                    []byte
 	count, next, prev, perPage string
 	UrlVars url.Values
+	err                        error
 }
 
 //Return the rawBody Var
@@ -1081,28 +1118,52 @@ func (p *Paging) initParams(params []url.Values) (sendvars url.Values){
 	return
 }
 
+//Return the error of the last Next, Prev or Start that returned false
+func (p *Paging) Err() error {
+	return p.err
+}
+
+//Total number of records in the list, from the X-Records header
+func (p *Paging) Count() int {
+	n, _ := strconv.Atoi(p.count)
+	return n
+}
+
+//Record the error of a page that could not be loaded
+func (p *Paging) setErr(err error) {
+	p.err = err
+}
+
+//Copy the params of this page for a request of the page at cursor
+func (p *Paging) cursorParams(cursor string) url.Values {
+	v := url.Values{}
+	for k, vals := range p.UrlVars {
+		v[k] = append([]string(nil), vals...)
+	}
+	if cursor == "" {
+		v.Del("cursor")
+	} else {
+		v.Set("cursor", cursor)
+	}
+	if p.perPage != "" {
+		v.Set("per_page", p.perPage)
+	}
+	return v
+}
+
 //Return params for next request
 func (p *Paging) NextParams() url.Values{
-	v := p.UrlVars
-	v.Set("cursor", p.next)
-	v.Set("per_page", p.perPage)
-	return v
+	return p.cursorParams(p.next)
 }
 
 //Return params for next request
 func (p *Paging) StartParams() url.Values{
-	v := p.UrlVars
-	v.Del("cursor")
-	v.Set("per_page", p.perPage)
-	return v
+	return p.cursorParams("")
 }
 
 //Return params for next request
 func (p *Paging) PrevParams() url.Values{
-	v := p.UrlVars
-	v.Set("cursor", p.prev)
-	v.Set("per_page", p.perPage)
-	return v
+	return p.cursorParams(p.prev)
 }
 
 //Set header data for paging
@@ -1114,14 +1175,18 @@ func (p *Paging) SetData(rb []byte, count string, links string, params url.Value
 	p.UrlVars = params
 	for _, v := range strings.SplitN(links, ",", -1) {
 		link := strings.SplitN(v, ";", -1)
+		if len(link) < 2 {
+			continue
+		}
+		link[0] = strings.TrimSpace(link[0])
 		link[0] = strings.Replace(link[0], "<", "", -1)
 		link[0] = strings.Replace(link[0], ">", "", -1)
 		if u, err := url.Parse(link[0]); err == nil {
 			values := u.Query()
-			switch link[1] {
-			case " rel=\"next\"":
+			switch strings.TrimSpace(link[1]) {
+			case "rel=\"next\"":
 				p.next = values.Get("cursor")
-			case " rel=\"prev\"":
+			case "rel=\"prev\"":
 				p.prev = values.Get("cursor")
 			}
 		}
@@ -1129,24 +1194,15 @@ func (p *Paging) SetData(rb []byte, count string, links string, params url.Value
 }
 
 //Initialize the paging list values
-func (p *Paging) initList(endpoint string, params url.Values, r *Recurly) error {
-	if resp, err := r.createRequest(endpoint, "GET", params, make([]byte, 0)); err == nil {
+func (p *Paging) initList(ctx context.Context, endpoint string, params url.Values, r *Recurly) (e error) {
+	ctx, endSpan := r.startSpan(withOperation(ctx, "list"), "GET", endpoint)
+	defer func() { endSpan(e) }()
+	if resp, err := r.createRequest(ctx, endpoint, "GET", params, make([]byte, 0)); err == nil {
 		if resp.StatusCode < 400 {
 			defer resp.Body.Close()
 			if body, readerr := ioutil.ReadAll(resp.Body); readerr == nil {
-				if r.debug {
-					println(resp.Status)
-					for k, _ := range resp.Header {
-						println(k + ":" + resp.Header[k][0])
-					}
-					fmt.Printf("%s\n", body)
-					fmt.Printf("Content-Length:%v\n", resp.ContentLength)
-				}
-				if x := len(resp.Header["Link"]); x > 0 {
-					p.SetData(body, resp.Header["X-Records"][0], resp.Header["Link"][0],params)
-				} else {
-					p.SetData(body, resp.Header["X-Records"][0], "",params)
-				}
+				r.logResponseBody(ctx, resp, body)
+				p.SetData(body, resp.Header.Get("X-Records"), resp.Header.Get("Link"), params)
 				//everything went fine
 				return nil
 			} else {
@@ -1154,7 +1210,7 @@ func (p *Paging) initList(endpoint string, params url.Values, r *Recurly) error
 				return readerr
 			}
 		} else {
-			return createRecurlyError(resp)
+			return r.createRecurlyError(resp)
 		}
 	} else {
 		//return error message
@@ -1163,6 +1219,25 @@ func (p *Paging) initList(endpoint string, params url.Values, r *Recurly) error
 	return nil
 }
 
+//Send a HEAD request for a list and return the X-Records header
+func (r *Recurly) count(ctx context.Context, endpoint string, params url.Values) (_ int, e error) {
+	ctx, endSpan := r.startSpan(withOperation(ctx, "count"), "HEAD", endpoint)
+	defer func() { endSpan(e) }()
+	resp, err := r.createRequest(ctx, endpoint, "HEAD", params, nil)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 400 {
+		return 0, r.createRecurlyError(resp)
+	}
+	resp.Body.Close()
+	n, err := strconv.Atoi(resp.Header.Get("X-Records"))
+	if err != nil {
+		return 0, fmt.Errorf("The X-Records header %q is not a count", resp.Header.Get("X-Records"))
+	}
+	return n, nil
+}
+
 /*resource objects */
 
 //A struct to help with marshalling currency
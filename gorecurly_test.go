@@ -695,13 +695,13 @@ func TestLive(t *testing.T) {
 		for _, invoice := range invoices.Invoices {
 			if marksuccesful {
 				//mark invoice as failed
-				if err = invoice.MarkFailed(); err != nil {
+				if _, err = invoice.MarkFailed(); err != nil {
 					t.Fatalf("Marking failed failed inv num:%s error:%s", invoice.InvoiceNumber, err.Error())
 				}
 			} else {
 				//mark invoice as successful
 				marksuccesful = true
-				if err = invoice.MarkSuccessful(); err != nil {
+				if _, err = invoice.MarkSuccessful(); err != nil {
 					t.Fatalf("Marking successful failed inv num:%s error:%s", invoice.InvoiceNumber, err.Error())
 				}
 			}
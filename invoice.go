@@ -1,8 +1,12 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"time"
 )
 
@@ -22,38 +26,283 @@ type Invoice struct {
 	TotalInCents int `xml:"total_in_cents,omitempty"`
 	Currency string `xml:"currency,omitempty"`
 	CreatedAt *time.Time `xml:"created_at,omitempty"`
-	LineItems []LineItems `xml:"line_items,omitempty"`
-	//Transactions []Transaction `xml:"transactions,omitempty"`
+	ClosedAt RecurlyDate `xml:"closed_at,omitempty"`
+	TaxType string `xml:"tax_type,omitempty"`
+	TaxRegion string `xml:"tax_region,omitempty"`
+	TaxRate float64 `xml:"tax_rate,omitempty"`
+	NetTerms int `xml:"net_terms,omitempty"`
+	CollectionMethod string `xml:"collection_method,omitempty"`
+	LineItems []Adjustment `xml:"line_items>adjustment"`
+	Transactions []Transaction `xml:"transactions>transaction"`
+}
+
+//Invoice Stub struct
+type InvoiceStub struct {
+	XMLName xml.Name `xml:"invoice"`
+	stub
+}
+
+//The successful payments of the invoice, refunds and failed attempts are left out
+func (i *Invoice) PaidTransactions() []Transaction {
+	var paid []Transaction
+	for _, t := range i.Transactions {
+		if t.Action == "purchase" && t.Status == "success" {
+			paid = append(paid, t)
+		}
+	}
+	return paid
+}
+
+//The amount in cents still due on the invoice
+func (i *Invoice) Balance() int {
+	balance := i.TotalInCents
+	for _, t := range i.PaidTransactions() {
+		balance -= t.AmountInCents
+	}
+	return balance
+}
+
+//The line items charged or credited for a subscription
+func (i *Invoice) LineItemsForSubscription(uuid string) []Adjustment {
+	var items []Adjustment
+	for _, a := range i.LineItems {
+		if a.Subscription != nil && a.Subscription.GetCode() == uuid {
+			items = append(items, a)
+		}
+	}
+	return items
 }
 
 //Invoice any pending charges given an acount code
 func (i *Invoice) InvoicePendingCharges(account_code string) error {
-	_, err := i.r.createRequest(ACCOUNTS + "/" + account_code + "/invoices", "POST", nil, nil)
-	return err
+	return i.InvoicePendingChargesCtx(context.Background(), account_code)
 }
 
-//Mark an invoice as successfully paid
-func (i *Invoice) MarkSuccessful() error {
-	if i.UUID == "" {
-		return errors.New("Not a valid invoice")
+//Same as InvoicePendingCharges, bound to a context
+func (i *Invoice) InvoicePendingChargesCtx(ctx context.Context, account_code string) error {
+	resp, err := i.r.createRequest(ctx, ACCOUNTS + "/" + account_code + "/invoices", "POST", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+	if resp.StatusCode >= 400 {
+		return i.r.createRecurlyError(resp)
 	}
-	_, err := i.r.createRequest(INVOICES + "/" + i.InvoiceNumber + "/mark_successful", "PUT", nil, nil)
-	return err
+	return nil
+}
+
+//Mark an invoice as successfully paid, returns the updated invoice
+func (i *Invoice) MarkSuccessful() (Invoice, error) {
+	return i.MarkSuccessfulCtx(context.Background())
 }
 
-//Mark an invoice as failed
-func (i *Invoice) MarkFailed() error {
-	if i.UUID == "" {
-		return errors.New("Not a valid invoice")
+//Same as MarkSuccessful, bound to a context
+func (i *Invoice) MarkSuccessfulCtx(ctx context.Context) (Invoice, error) {
+	return i.action(ctx, "mark_successful")
+}
+
+//Mark an invoice as failed, returns the updated invoice
+func (i *Invoice) MarkFailed() (Invoice, error) {
+	return i.MarkFailedCtx(context.Background())
+}
+
+//Same as MarkFailed, bound to a context
+func (i *Invoice) MarkFailedCtx(ctx context.Context) (Invoice, error) {
+	return i.action(ctx, "mark_failed")
+}
+
+//Retry collecting a failed or past due invoice, returns the updated invoice
+func (i *Invoice) Collect() (Invoice, error) {
+	return i.CollectCtx(context.Background())
+}
+
+//Same as Collect, bound to a context
+func (i *Invoice) CollectCtx(ctx context.Context) (Invoice, error) {
+	return i.action(ctx, "collect")
+}
+
+//Void an open or failed invoice, returns the updated invoice
+func (i *Invoice) Void() (Invoice, error) {
+	return i.VoidCtx(context.Background())
+}
+
+//Same as Void, bound to a context
+func (i *Invoice) VoidCtx(ctx context.Context) (Invoice, error) {
+	return i.action(ctx, "void")
+}
+
+//Methods of payments collected outside of Recurly
+type OfflinePaymentMethod string
+
+const (
+	PaymentCheck        OfflinePaymentMethod = "check"
+	PaymentWireTransfer OfflinePaymentMethod = "wire_transfer"
+	PaymentMoneyOrder   OfflinePaymentMethod = "money_order"
+	PaymentEFT          OfflinePaymentMethod = "eft"
+	PaymentOther        OfflinePaymentMethod = "other"
+)
+
+type offlinePayment struct {
+	XMLName       xml.Name             `xml:"transaction"`
+	PaymentMethod OfflinePaymentMethod `xml:"payment_method"`
+	AmountInCents int                  `xml:"amount_in_cents"`
+	Description   string               `xml:"description,omitempty"`
+	CollectedAt   *time.Time           `xml:"collected_at,omitempty"`
+}
+
+//Returned by RecordPayment along with the transaction when the payment was recorded but the invoice could not be reloaded
+var ErrInvoiceReload = errors.New("The payment was recorded but the invoice could not be reloaded.")
+
+//Record a payment collected outside of Recurly, e.g. a check, against a manual invoice.
+//reference is stored as the description of the transaction, a zero collectedAt means now.
+//Returns the invoice reloaded after the payment and the transaction of the payment.
+//When the invoice can not be reloaded afterwards the payment is recorded all the same, the
+//transaction is returned with the zero Invoice and an error matching ErrInvoiceReload.  Do not
+//retry then, load the invoice again with GetInvoice.
+func (i *Invoice) RecordPayment(method OfflinePaymentMethod, amount_in_cents int, reference string, collectedAt time.Time) (Invoice, Transaction, error) {
+	return i.RecordPaymentCtx(context.Background(), method, amount_in_cents, reference, collectedAt)
+}
+
+//Same as RecordPayment, bound to a context
+func (i *Invoice) RecordPaymentCtx(ctx context.Context, method OfflinePaymentMethod, amount_in_cents int, reference string, collectedAt time.Time) (Invoice, Transaction, error) {
+	if i.InvoiceNumber == "" {
+		return Invoice{}, Transaction{}, errors.New("Not a valid invoice")
+	}
+	if amount_in_cents <= 0 {
+		return Invoice{}, Transaction{}, errors.New("The payment amount must be positive")
+	}
+	payment := offlinePayment{PaymentMethod: method, AmountInCents: amount_in_cents, Description: reference}
+	if !collectedAt.IsZero() {
+		payment.CollectedAt = &collectedAt
 	}
-	_, err := i.r.createRequest(INVOICES + "/" + i.InvoiceNumber + "/mark_failed", "PUT", nil, nil)
-	return err
+	tran := i.r.NewTransaction()
+	if err := i.r.doCreateReturn(withOperation(ctx, "record_payment"), payment, &tran, INVOICES+"/"+i.InvoiceNumber+"/transactions"); err != nil {
+		return Invoice{}, Transaction{}, err
+	}
+	updated, err := i.r.GetInvoiceCtx(ctx, i.InvoiceNumber)
+	if err != nil {
+		return Invoice{}, tran, fmt.Errorf("%w: %w", ErrInvoiceReload, err)
+	}
+	return updated, tran, nil
+}
+
+//PUT an action of the invoice and return the invoice from the response
+func (i *Invoice) action(ctx context.Context, action string) (Invoice, error) {
+	if i.InvoiceNumber == "" {
+		return Invoice{}, errors.New("Not a valid invoice")
+	}
+	resp, err := i.r.createRequest(withOperation(ctx, action), INVOICES+"/"+i.InvoiceNumber+"/"+action, "PUT", nil, nil)
+	if err != nil {
+		return Invoice{}, err
+	}
+	if resp.StatusCode >= 400 {
+		return Invoice{}, i.r.createRecurlyError(resp)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Invoice{}, err
+	}
+	i.r.logResponseBody(ctx, resp, body)
+	updated := i.r.NewInvoice()
+	if err := xml.Unmarshal(body, &updated); err != nil {
+		return Invoice{}, err
+	}
+	updated.attach(i.r)
+	return updated, nil
+}
+
+//Download the pdf of the invoice in en-US, use GetInvoicePDF for other languages.
+//The body is streamed from Recurly and must be closed.
+func (i *Invoice) PDF(ctx context.Context) (io.ReadCloser, error) {
+	return i.r.GetInvoicePDFCtx(ctx, i.InvoiceNumber, "")
+}
+
+//Write the pdf of the invoice to w without buffering it
+func (i *Invoice) WritePDFTo(w io.Writer) (int64, error) {
+	return i.WritePDFToCtx(context.Background(), w)
+}
+
+//Same as WritePDFTo, bound to a context
+func (i *Invoice) WritePDFToCtx(ctx context.Context, w io.Writer) (int64, error) {
+	body, err := i.PDF(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	return io.Copy(w, body)
+}
+
+//How a refund is split between the credit payments and the transactions of an invoice
+type RefundMethod string
+
+const (
+	RefundCreditFirst      RefundMethod = "credit_first"
+	RefundTransactionFirst RefundMethod = "transaction_first"
+)
+
+//A line item of an invoice to refund, Prorate only refunds the unused part of its service period
+type RefundLineItem struct {
+	UUID     string `xml:"uuid"`
+	Quantity int    `xml:"quantity"`
+	Prorate  bool   `xml:"prorate"`
+}
+
+type invoiceRefund struct {
+	XMLName       xml.Name         `xml:"invoice"`
+	AmountInCents int              `xml:"amount_in_cents,omitempty"`
+	LineItems     *refundLineItems `xml:"line_items,omitempty"`
+	RefundMethod  RefundMethod     `xml:"refund_method,omitempty"`
+}
+
+type refundLineItems struct {
+	Adjustments []RefundLineItem `xml:"adjustment"`
+}
+
+//Refund an amount of the invoice, returns the credit invoice of the refund
+func (i *Invoice) RefundAmount(amount_in_cents int, method RefundMethod) (Invoice, error) {
+	return i.RefundAmountCtx(context.Background(), amount_in_cents, method)
 }
 
+//Same as RefundAmount, bound to a context
+func (i *Invoice) RefundAmountCtx(ctx context.Context, amount_in_cents int, method RefundMethod) (Invoice, error) {
+	if amount_in_cents <= 0 {
+		return Invoice{}, errors.New("The refund amount must be positive")
+	}
+	return i.refund(ctx, invoiceRefund{AmountInCents: amount_in_cents, RefundMethod: method})
+}
 
-//Listing of line items in a transaction
-type LineItems struct {
-	XMLName    xml.Name `xml:"line_items"`
-	Adjustment []Adjustment
+//Refund line items of the invoice, e.g. a single add on charge, returns the credit invoice of the refund
+func (i *Invoice) RefundLineItems(items []RefundLineItem, method RefundMethod) (Invoice, error) {
+	return i.RefundLineItemsCtx(context.Background(), items, method)
 }
 
+//Same as RefundLineItems, bound to a context
+func (i *Invoice) RefundLineItemsCtx(ctx context.Context, items []RefundLineItem, method RefundMethod) (Invoice, error) {
+	if len(items) == 0 {
+		return Invoice{}, errors.New("No line items to refund")
+	}
+	return i.refund(ctx, invoiceRefund{LineItems: &refundLineItems{items}, RefundMethod: method})
+}
+
+func (i *Invoice) refund(ctx context.Context, refund invoiceRefund) (Invoice, error) {
+	if i.InvoiceNumber == "" {
+		return Invoice{}, errors.New("Not a valid invoice")
+	}
+	credit := i.r.NewInvoice()
+	err := i.r.doCreateReturn(withOperation(ctx, "refund"), refund, &credit, INVOICES+"/"+i.InvoiceNumber+"/refund")
+	credit.attach(i.r)
+	return credit, err
+}
+
+//Bind the invoice, its line items and transactions to a client after it is decoded
+func (i *Invoice) attach(r *Recurly) {
+	i.r = r
+	i.endpoint = INVOICES
+	for k := range i.LineItems {
+		i.LineItems[k].attach(r)
+	}
+	for k := range i.Transactions {
+		i.Transactions[k].attach(r)
+	}
+}
@@ -1,7 +1,10 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
+	"iter"
+	"net/url"
 )
 
 //The invoice list struct
@@ -15,32 +18,38 @@ type InvoiceList struct {
 
 //Get next set of invoices
 func (i *InvoiceList) Next() (bool) {
-	if i.next != "" {
-		*i,_ = i.r.GetInvoices(i.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return i.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (i *InvoiceList) NextCtx(ctx context.Context) bool {
+	return movePage(i, i.next, func() (InvoiceList, error) {
+		return i.r.GetInvoicesCtx(ctx, i.NextParams())
+	})
 }
 
 //Get previous set of invoices
 func (i *InvoiceList) Prev() ( bool) {
-	if i.prev != "" {
-		*i,_ = i.r.GetInvoices(i.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return i.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (i *InvoiceList) PrevCtx(ctx context.Context) bool {
+	return movePage(i, i.prev, func() (InvoiceList, error) {
+		return i.r.GetInvoicesCtx(ctx, i.PrevParams())
+	})
 }
 
 //Go to start set of invoices
 func (i *InvoiceList) Start() ( bool) {
-	if i.prev != "" {
-		*i,_ = i.r.GetInvoices(i.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return i.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (i *InvoiceList) StartCtx(ctx context.Context) bool {
+	return movePage(i, i.prev, func() (InvoiceList, error) {
+		return i.r.GetInvoicesCtx(ctx, i.StartParams())
+	})
 }
 
 //Get the list of invoices by account
@@ -52,30 +61,48 @@ type AccountInvoiceList struct {
 
 //Get next set of invoices by account
 func (a *AccountInvoiceList) Next() (bool) {
-	if a.next != "" {
-		*a,_ = a.r.GetAccountInvoices(a.AccountCode,a.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return a.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (a *AccountInvoiceList) NextCtx(ctx context.Context) bool {
+	return movePage(a, a.next, func() (AccountInvoiceList, error) {
+		return a.r.GetAccountInvoicesCtx(ctx, a.AccountCode,a.NextParams())
+	})
 }
 
 //Get previous set of invoices by account
 func (a *AccountInvoiceList) Prev() ( bool) {
-	if a.prev != "" {
-		*a,_ = a.r.GetAccountInvoices(a.AccountCode,a.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return a.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (a *AccountInvoiceList) PrevCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AccountInvoiceList, error) {
+		return a.r.GetAccountInvoicesCtx(ctx, a.AccountCode,a.PrevParams())
+	})
 }
 
 //Go to start set of invoices by account
 func (a *AccountInvoiceList) Start() ( bool) {
-	if a.prev != "" {
-		*a,_ = a.r.GetAccountInvoices(a.AccountCode,a.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return a.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (a *AccountInvoiceList) StartCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AccountInvoiceList, error) {
+		return a.r.GetAccountInvoicesCtx(ctx, a.AccountCode,a.StartParams())
+	})
+}
+
+//Iterate over all invoices, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) Invoices(ctx context.Context, params ...url.Values) iter.Seq2[Invoice, error] {
+	return listAll[Invoice](ctx, r, INVOICES, params)
+}
+
+//Iterate over all invoices for an account_code, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) AccountInvoices(ctx context.Context, account_code string, params ...url.Values) iter.Seq2[Invoice, error] {
+	return listAll[Invoice](ctx, r, ACCOUNTS+"/"+account_code+"/"+INVOICES, params)
 }
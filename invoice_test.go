@@ -0,0 +1,321 @@
+package gorecurly
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvoicePDF(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/invoices/1001" {
+			w.WriteHeader(404)
+			return
+		}
+		if r.Header.Get("Accept") != "application/pdf" {
+			w.WriteHeader(406)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		io.WriteString(w, "%PDF-1.4 "+r.Header.Get("Accept-Language"))
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	body, err := r.GetInvoicePDF("1001", "de-DE")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	b, _ := io.ReadAll(body)
+	body.Close()
+	if string(b) != "%PDF-1.4 de-DE" {
+		t.Fatalf("Unexpected pdf %q", b)
+	}
+
+	inv := Invoice{r: r, InvoiceNumber: "1001"}
+	var buf bytes.Buffer
+	if n, err := inv.WritePDFTo(&buf); err != nil || n != int64(buf.Len()) || buf.String() != "%PDF-1.4 en-US" {
+		t.Fatalf("Unexpected pdf %q %v", buf.String(), err)
+	}
+	inv.InvoiceNumber = "1002"
+	if _, err := inv.PDF(context.Background()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	//other requests still ask for xml
+	if _, err := r.GetInvoice("1001"); !errors.Is(err, ErrNotAcceptable) {
+		t.Fatalf("Expected the xml request to be refused, got %v", err)
+	}
+}
+
+func TestInvoiceRefund(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/invoices/1001/refund" {
+			w.WriteHeader(404)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, regexp.MustCompile(`>\s+<`).ReplaceAllString(string(b), "><"))
+		w.WriteHeader(201)
+		io.WriteString(w, `<invoice href="https://api.recurly.com/v2/invoices/1002"><uuid>421f7b7d414e4c6792938e7c49d552e9</uuid><state>open</state><invoice_number>1002</invoice_number><total_in_cents>-500</total_in_cents></invoice>`)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	inv := r.NewInvoice()
+	inv.InvoiceNumber = "1001"
+	credit, err := inv.RefundAmount(500, RefundTransactionFirst)
+	if err != nil || credit.InvoiceNumber != "1002" || credit.TotalInCents != -500 || credit.r != r {
+		t.Fatalf("Unexpected credit invoice %+v %v", credit, err)
+	}
+	_, err = inv.RefundLineItems([]RefundLineItem{{UUID: "2bc33a7469dc1458f455634212acdcd6", Quantity: 1, Prorate: true}}, RefundCreditFirst)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(bodies[0], "<amount_in_cents>500</amount_in_cents>") || !strings.Contains(bodies[0], "<refund_method>transaction_first</refund_method>") || strings.Contains(bodies[0], "line_items") {
+		t.Fatalf("Unexpected amount refund %v", bodies[0])
+	}
+	if !strings.Contains(bodies[1], "<line_items><adjustment><uuid>2bc33a7469dc1458f455634212acdcd6</uuid><quantity>1</quantity><prorate>true</prorate></adjustment></line_items>") || strings.Contains(bodies[1], "amount_in_cents") {
+		t.Fatalf("Unexpected line item refund %v", bodies[1])
+	}
+	if _, err := inv.RefundAmount(0, RefundCreditFirst); err == nil || len(bodies) != 2 {
+		t.Fatal("Expected an empty refund to be refused")
+	}
+}
+
+func TestInvoiceLifecycle(t *testing.T) {
+	state := "failed"
+	reloadFails := false
+	var payment string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "PUT /invoices/1001/collect":
+			state = "collected"
+		case "PUT /invoices/1001/void":
+			w.WriteHeader(400)
+			io.WriteString(w, `<error><symbol>invalid_transition</symbol><description>The invoice cannot be voided</description></error>`)
+			return
+		case "PUT /invoices/1001/mark_failed":
+			state = "failed"
+		case "POST /invoices/1001/transactions":
+			b, _ := io.ReadAll(r.Body)
+			payment = regexp.MustCompile(`>\s+<`).ReplaceAllString(string(b), "><")
+			state = "collected"
+			w.WriteHeader(201)
+			io.WriteString(w, `<transaction><uuid>abc</uuid><amount_in_cents>1000</amount_in_cents></transaction>`)
+			return
+		case "GET /invoices/1001":
+			if reloadFails {
+				w.WriteHeader(500)
+				return
+			}
+		default:
+			w.WriteHeader(404)
+			return
+		}
+		io.WriteString(w, `<invoice><uuid>421f7b7d414e4c6792938e7c49d552e9</uuid><state>`+state+`</state><invoice_number>1001</invoice_number></invoice>`)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	inv := r.NewInvoice()
+	inv.InvoiceNumber = "1001"
+	if updated, err := inv.Collect(); err != nil || updated.State != "collected" || updated.UUID == "" || updated.r != r {
+		t.Fatalf("Unexpected invoice after collect %+v %v", updated, err)
+	}
+	if inv.State != "" {
+		t.Fatal("Expected the receiver to be left alone")
+	}
+	if _, err := inv.Void(); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("Expected the void to fail, got %v", err)
+	}
+	if updated, err := inv.MarkFailed(); err != nil || updated.State != "failed" {
+		t.Fatalf("Unexpected invoice after mark failed %+v %v", updated, err)
+	}
+	collected := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	updated, tran, err := inv.RecordPayment(PaymentCheck, 1000, "check 4711", collected)
+	if err != nil || updated.State != "collected" || updated.r != r || tran.UUID != "abc" {
+		t.Fatalf("Unexpected invoice after payment %+v %+v %v", updated, tran, err)
+	}
+	if payment != "<?xml version=\"1.0\" encoding=\"UTF-8\"?><transaction><payment_method>check</payment_method><amount_in_cents>1000</amount_in_cents><description>check 4711</description><collected_at>2024-03-01T12:00:00Z</collected_at></transaction>" {
+		t.Fatalf("Unexpected payment %q", payment)
+	}
+	//the payment is recorded even if the invoice can not be reloaded
+	reloadFails = true
+	updated, tran, err = inv.RecordPayment(PaymentCheck, 1000, "check 4712", collected)
+	if !errors.Is(err, ErrInvoiceReload) || !errors.Is(err, ErrServer) || tran.UUID != "abc" || updated.InvoiceNumber != "" {
+		t.Fatalf("Expected the recorded payment with a reload error, got %+v %+v %v", updated, tran, err)
+	}
+	inv = r.NewInvoice()
+	inv.UUID = "421f7b7d414e4c6792938e7c49d552e9"
+	if _, err := inv.MarkSuccessful(); err == nil {
+		t.Fatal("Expected an invoice without a number to be refused")
+	}
+}
+
+const fullInvoice = `<?xml version="1.0" encoding="UTF-8"?>
+<invoice href="https://api.recurly.com/v2/invoices/1005">
+  <account href="https://api.recurly.com/v2/accounts/1"/>
+  <uuid>421f7b7d414e4c6792938e7c49d552e9</uuid>
+  <state>open</state>
+  <invoice_number type="integer">1005</invoice_number>
+  <subtotal_in_cents type="integer">3000</subtotal_in_cents>
+  <tax_in_cents type="integer">263</tax_in_cents>
+  <total_in_cents type="integer">3263</total_in_cents>
+  <currency>USD</currency>
+  <created_at type="datetime">2016-07-01T10:20:30Z</created_at>
+  <closed_at nil="nil"></closed_at>
+  <tax_type>usst</tax_type>
+  <tax_rate type="float">0.0875</tax_rate>
+  <net_terms type="integer">30</net_terms>
+  <collection_method>manual</collection_method>
+  <line_items type="array">
+    <adjustment href="https://api.recurly.com/v2/adjustments/a1" type="charge">
+      <account href="https://api.recurly.com/v2/accounts/1"/>
+      <invoice href="https://api.recurly.com/v2/invoices/1005"/>
+      <subscription href="https://api.recurly.com/v2/subscriptions/s1"/>
+      <uuid>a1</uuid>
+      <state>invoiced</state>
+      <description>Gold plan</description>
+      <product_code>gold</product_code>
+      <origin>plan</origin>
+      <unit_amount_in_cents type="integer">2000</unit_amount_in_cents>
+      <quantity type="integer">1</quantity>
+      <tax_in_cents type="integer">175</tax_in_cents>
+      <total_in_cents type="integer">2175</total_in_cents>
+      <proration_rate type="float">0.5</proration_rate>
+      <tax_type>usst</tax_type>
+      <tax_region>CA</tax_region>
+      <tax_rate type="float">0.0875</tax_rate>
+      <tax_exempt type="boolean">false</tax_exempt>
+      <tax_details type="array">
+        <tax_detail>
+          <name>california</name>
+          <type>state</type>
+          <tax_rate type="float">0.065</tax_rate>
+          <tax_in_cents type="integer">130</tax_in_cents>
+        </tax_detail>
+        <tax_detail>
+          <name>san francisco</name>
+          <type>county</type>
+          <tax_rate type="float">0.0225</tax_rate>
+          <tax_in_cents type="integer">45</tax_in_cents>
+        </tax_detail>
+      </tax_details>
+      <start_date type="datetime">2016-07-01T10:20:30Z</start_date>
+      <end_date type="datetime">2016-08-01T10:20:30Z</end_date>
+      <created_at type="datetime">2016-07-01T10:20:30Z</created_at>
+    </adjustment>
+    <adjustment href="https://api.recurly.com/v2/adjustments/a2" type="charge">
+      <subscription href="https://api.recurly.com/v2/subscriptions/s2"/>
+      <uuid>a2</uuid>
+      <unit_amount_in_cents type="integer">1000</unit_amount_in_cents>
+      <quantity type="integer">1</quantity>
+      <total_in_cents type="integer">1088</total_in_cents>
+      <end_date nil="nil"></end_date>
+    </adjustment>
+  </line_items>
+  <transactions type="array">
+    <transaction href="https://api.recurly.com/v2/transactions/t1" type="credit_card">
+      <account href="https://api.recurly.com/v2/accounts/1"/>
+      <invoice href="https://api.recurly.com/v2/invoices/1005"/>
+      <subscription href="https://api.recurly.com/v2/subscriptions/s1"/>
+      <uuid>t1</uuid>
+      <action>purchase</action>
+      <amount_in_cents type="integer">1000</amount_in_cents>
+      <status>success</status>
+      <payment_method>credit_card</payment_method>
+      <source>subscription</source>
+      <recurring type="boolean">true</recurring>
+    </transaction>
+    <transaction href="https://api.recurly.com/v2/transactions/t2" type="credit_card">
+      <uuid>t2</uuid>
+      <action>purchase</action>
+      <amount_in_cents type="integer">2263</amount_in_cents>
+      <status>declined</status>
+    </transaction>
+  </transactions>
+</invoice>`
+
+func TestInvoiceDecode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, fullInvoice)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	inv, err := r.GetInvoice("1005")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if inv.TaxRate != 0.0875 || inv.NetTerms != 30 || inv.CollectionMethod != "manual" {
+		t.Fatalf("Unexpected invoice %+v", inv)
+	}
+	if len(inv.LineItems) != 2 || len(inv.Transactions) != 2 {
+		t.Fatalf("Expected 2 line items and 2 transactions, got %d and %d", len(inv.LineItems), len(inv.Transactions))
+	}
+	item := inv.LineItems[0]
+	if item.r != r || item.Invoice.GetCode() != "1005" || item.TotalInCents != 2175 || !item.Prorated() || item.TaxRegion != "CA" {
+		t.Fatalf("Unexpected line item %+v", item)
+	}
+	if item.TaxDetails == nil || len(item.TaxDetails.Details) != 2 || item.TaxDetails.Details[1].TaxInCents != 45 {
+		t.Fatalf("Unexpected tax details %+v", item.TaxDetails)
+	}
+	if inv.LineItems[1].Prorated() {
+		t.Fatal("A line item without a proration rate is not prorated")
+	}
+	tran := inv.Transactions[0]
+	if tran.r != r || tran.Subscription.GetCode() != "s1" || tran.PaymentMethod != "credit_card" || !tran.Recurring {
+		t.Fatalf("Unexpected transaction %+v", tran)
+	}
+
+	if paid := inv.PaidTransactions(); len(paid) != 1 || paid[0].UUID != "t1" {
+		t.Fatalf("Unexpected paid transactions %+v", paid)
+	}
+	if inv.Balance() != 2263 {
+		t.Fatalf("Expected a balance of 2263, got %d", inv.Balance())
+	}
+	if items := inv.LineItemsForSubscription("s2"); len(items) != 1 || items[0].UUID != "a2" {
+		t.Fatalf("Unexpected line items %+v", items)
+	}
+	if items := inv.LineItemsForSubscription("s3"); len(items) != 0 {
+		t.Fatalf("Unexpected line items %+v", items)
+	}
+}
+
+func TestInvoicePendingCharges(t *testing.T) {
+	status := 201
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/accounts/1/invoices" {
+			w.WriteHeader(404)
+			return
+		}
+		w.WriteHeader(status)
+		if status == 422 {
+			io.WriteString(w, `<errors><error field="base" symbol="will_not_invoice">No charges to invoice</error></errors>`)
+			return
+		}
+		io.WriteString(w, `<invoice><invoice_number>1001</invoice_number></invoice>`)
+	}))
+	defer ts.Close()
+
+	inv := NewClient("", WithBaseURL(ts.URL)).NewInvoice()
+	if err := inv.InvoicePendingCharges("1"); err != nil {
+		t.Fatal(err.Error())
+	}
+	status = 422
+	if err := inv.InvoicePendingCharges("1"); !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	status = 503
+	if err := inv.InvoicePendingCharges("1"); !errors.Is(err, ErrServer) {
+		t.Fatalf("Expected a server error, got %v", err)
+	}
+}
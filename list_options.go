@@ -0,0 +1,210 @@
+package gorecurly
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+//Returned by Values when a filter has a value Recurly does not accept
+var ErrInvalidListOption = errors.New("The list option is not valid for this resource.")
+
+//Sort field for lists
+type ListSort string
+
+const (
+	SortCreatedAt ListSort = "created_at"
+	SortUpdatedAt ListSort = "updated_at"
+)
+
+//Sort order for lists
+type ListOrder string
+
+const (
+	OrderAsc  ListOrder = "asc"
+	OrderDesc ListOrder = "desc"
+)
+
+//Account states that can be used as a list filter
+type AccountState string
+
+const (
+	AccountStateActive        AccountState = "active"
+	AccountStateClosed        AccountState = "closed"
+	AccountStatePastDue       AccountState = "past_due"
+	AccountStateSubscriber    AccountState = "subscriber"
+	AccountStateNonSubscriber AccountState = "non_subscriber"
+)
+
+//Subscription states that can be used as a list filter
+type SubscriptionState string
+
+const (
+	SubscriptionStateActive   SubscriptionState = "active"
+	SubscriptionStateCanceled SubscriptionState = "canceled"
+	SubscriptionStateExpired  SubscriptionState = "expired"
+	SubscriptionStateFuture   SubscriptionState = "future"
+	SubscriptionStateInTrial  SubscriptionState = "in_trial"
+	SubscriptionStateLive     SubscriptionState = "live"
+	SubscriptionStatePastDue  SubscriptionState = "past_due"
+)
+
+//Transaction types that can be used as a list filter
+type TransactionType string
+
+const (
+	TransactionTypeAuthorization TransactionType = "authorization"
+	TransactionTypePurchase      TransactionType = "purchase"
+	TransactionTypeRefund        TransactionType = "refund"
+	TransactionTypeVerify        TransactionType = "verify"
+)
+
+//Transaction states that can be used as a list filter
+type TransactionState string
+
+const (
+	TransactionStateSuccessful TransactionState = "successful"
+	TransactionStateFailed     TransactionState = "failed"
+	TransactionStateVoided     TransactionState = "voided"
+)
+
+//Invoice states that can be used as a list filter
+type InvoiceState string
+
+const (
+	InvoiceStateOpen       InvoiceState = "open"
+	InvoiceStatePending    InvoiceState = "pending"
+	InvoiceStateProcessing InvoiceState = "processing"
+	InvoiceStateCollected  InvoiceState = "collected"
+	InvoiceStatePaid       InvoiceState = "paid"
+	InvoiceStateFailed     InvoiceState = "failed"
+	InvoiceStatePastDue    InvoiceState = "past_due"
+)
+
+//Filters shared by every list, zero values are left out
+type ListOptions struct {
+	BeginTime time.Time
+	EndTime   time.Time
+	Sort      ListSort
+	Order     ListOrder
+	PerPage   int
+}
+
+//Encode the options into params for a Get*s call or a list iterator
+func (o ListOptions) Values() (url.Values, error) {
+	v := url.Values{}
+	if !o.BeginTime.IsZero() {
+		v.Set("begin_time", o.BeginTime.UTC().Format(time.RFC3339))
+	}
+	if !o.EndTime.IsZero() {
+		v.Set("end_time", o.EndTime.UTC().Format(time.RFC3339))
+	}
+	if !o.BeginTime.IsZero() && !o.EndTime.IsZero() && o.EndTime.Before(o.BeginTime) {
+		return nil, fmt.Errorf("%w (end_time is before begin_time)", ErrInvalidListOption)
+	}
+	if err := setEnum(v, "sort", string(o.Sort), SortCreatedAt, SortUpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := setEnum(v, "order", string(o.Order), OrderAsc, OrderDesc); err != nil {
+		return nil, err
+	}
+	if o.PerPage != 0 {
+		if o.PerPage < 1 || o.PerPage > 200 {
+			return nil, fmt.Errorf("%w (per_page must be between 1 and 200, got %d)", ErrInvalidListOption, o.PerPage)
+		}
+		v.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	return v, nil
+}
+
+//Filters for GetAccounts
+type AccountListOptions struct {
+	ListOptions
+	State AccountState
+}
+
+//Encode the options into params for GetAccounts or Accounts
+func (o AccountListOptions) Values() (url.Values, error) {
+	v, err := o.ListOptions.Values()
+	if err != nil {
+		return nil, err
+	}
+	if err := setEnum(v, "state", string(o.State), AccountStateActive, AccountStateClosed, AccountStatePastDue, AccountStateSubscriber, AccountStateNonSubscriber); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+//Filters for GetSubscriptions and GetAccountSubscriptions
+type SubscriptionListOptions struct {
+	ListOptions
+	State SubscriptionState
+}
+
+//Encode the options into params for GetSubscriptions or Subscriptions
+func (o SubscriptionListOptions) Values() (url.Values, error) {
+	v, err := o.ListOptions.Values()
+	if err != nil {
+		return nil, err
+	}
+	if err := setEnum(v, "state", string(o.State), SubscriptionStateActive, SubscriptionStateCanceled, SubscriptionStateExpired, SubscriptionStateFuture, SubscriptionStateInTrial, SubscriptionStateLive, SubscriptionStatePastDue); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+//Filters for GetTransactions and GetAccountTransactions
+type TransactionListOptions struct {
+	ListOptions
+	Type  TransactionType
+	State TransactionState
+}
+
+//Encode the options into params for GetTransactions or Transactions
+func (o TransactionListOptions) Values() (url.Values, error) {
+	v, err := o.ListOptions.Values()
+	if err != nil {
+		return nil, err
+	}
+	if err := setEnum(v, "type", string(o.Type), TransactionTypeAuthorization, TransactionTypePurchase, TransactionTypeRefund, TransactionTypeVerify); err != nil {
+		return nil, err
+	}
+	if err := setEnum(v, "state", string(o.State), TransactionStateSuccessful, TransactionStateFailed, TransactionStateVoided); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+//Filters for GetInvoices and GetAccountInvoices
+type InvoiceListOptions struct {
+	ListOptions
+	State InvoiceState
+}
+
+//Encode the options into params for GetInvoices or Invoices
+func (o InvoiceListOptions) Values() (url.Values, error) {
+	v, err := o.ListOptions.Values()
+	if err != nil {
+		return nil, err
+	}
+	if err := setEnum(v, "state", string(o.State), InvoiceStateOpen, InvoiceStatePending, InvoiceStateProcessing, InvoiceStateCollected, InvoiceStatePaid, InvoiceStateFailed, InvoiceStatePastDue); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+//Set key to value if it is one of allowed, an empty value is left out
+func setEnum[E ~string](v url.Values, key, value string, allowed ...E) error {
+	if value == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if string(a) == value {
+			v.Set(key, value)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w (%s %q)", ErrInvalidListOption, key, value)
+}
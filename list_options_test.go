@@ -0,0 +1,39 @@
+package gorecurly
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestListOptions(t *testing.T) {
+	begin := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("EST", -5*3600))
+	v, err := AccountListOptions{ListOptions{BeginTime: begin, Sort: SortUpdatedAt, Order: OrderDesc, PerPage: 200}, AccountStatePastDue}.Values()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v.Encode() != "begin_time=2024-01-02T08%3A04%3A05Z&order=desc&per_page=200&sort=updated_at&state=past_due" {
+		t.Fatalf("Unexpected params %v", v.Encode())
+	}
+	var p Paging
+	if p.initParams([]url.Values{v}); p.perPage != "200" {
+		t.Fatalf("Expected per_page to be used for paging, got %v", p.perPage)
+	}
+
+	bad := []interface{ Values() (url.Values, error) }{
+		SubscriptionListOptions{State: "trialing"},
+		TransactionListOptions{Type: TransactionTypeRefund, State: "declined"},
+		InvoiceListOptions{ListOptions: ListOptions{PerPage: 201}},
+		ListOptions{BeginTime: begin, EndTime: begin.Add(-time.Hour)},
+		ListOptions{Order: "up"},
+	}
+	for _, o := range bad {
+		if _, err := o.Values(); !errors.Is(err, ErrInvalidListOption) {
+			t.Fatalf("Expected %+v to be rejected, got %v", o, err)
+		}
+	}
+	if v, _ := (TransactionListOptions{Type: TransactionTypeRefund, State: TransactionStateFailed}).Values(); v.Encode() != "state=failed&type=refund" {
+		t.Fatalf("Unexpected params %v", v.Encode())
+	}
+}
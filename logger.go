@@ -0,0 +1,78 @@
+package gorecurly
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+//A Logger receives the client's diagnostic output as structured records.
+//*slog.Logger implements it, so any slog handler can be used.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+//Send the client's log records to logger.  Every request is logged at debug
+//level, or warn level when it fails, with its method, endpoint, status,
+//duration and request id.  Request and response bodies are only logged
+//after EnableDebug.
+func WithLogger(logger Logger) Option {
+	return func(r *Recurly) {
+		r.logger = logger
+	}
+}
+
+//Log a record if a logger is set
+func (r *Recurly) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if r.logger != nil {
+		r.logger.Log(ctx, level, msg, args...)
+	}
+}
+
+//Logger used by EnableDebug when none was set, writes to stdout
+func newDebugLogger() Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+//Log the outcome of a single request attempt
+func (r *Recurly) logAttempt(ctx context.Context, method, endpoint string, attempt int, start time.Time, resp *http.Response, err error) {
+	args := []any{
+		slog.String("method", method),
+		slog.String("endpoint", endpoint),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		r.log(ctx, slog.LevelWarn, "recurly request failed", append(args, slog.Any("error", err))...)
+		return
+	}
+	args = append(args, slog.Int("status", resp.StatusCode), slog.String("request_id", resp.Header.Get("X-Request-Id")))
+	if resp.StatusCode >= 400 {
+		r.log(ctx, slog.LevelWarn, "recurly request", args...)
+	} else {
+		r.log(ctx, slog.LevelDebug, "recurly request", args...)
+	}
+}
+
+//Log the body of a request, only in debug mode.  Sensitive fields are redacted.
+func (r *Recurly) logRequestBody(ctx context.Context, method, u string, body []byte) {
+	if r.debug {
+		r.log(ctx, slog.LevelDebug, "recurly request body",
+			slog.String("method", method),
+			slog.String("url", r.redactor.url(u)),
+			slog.String("body", r.redactor.body(body)))
+	}
+}
+
+//Log the headers and body of a response, only in debug mode.  Sensitive fields are redacted.
+func (r *Recurly) logResponseBody(ctx context.Context, resp *http.Response, body []byte) {
+	if r.debug {
+		r.log(ctx, slog.LevelDebug, "recurly response body",
+			slog.Int("status", resp.StatusCode),
+			slog.Any("headers", r.redactor.header(resp.Header)),
+			slog.Int64("content_length", resp.ContentLength),
+			slog.String("body", r.redactor.body(body)))
+	}
+}
@@ -0,0 +1,79 @@
+package gorecurly
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		fmt.Fprintf(w, "%s", accountGet)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	r := NewClient("", WithBaseURL(ts.URL), WithLogger(logger))
+	if _, e := r.GetAccount("test21"); e != nil {
+		t.Fatal(e.Error())
+	}
+	out := buf.String()
+	for _, expected := range []string{"method=GET", "endpoint=accounts/test21", "status=200", "request_id=req-123", "duration="} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected %s in log output: %s", expected, out)
+		}
+	}
+	if strings.Contains(out, "hosted_login_token") {
+		t.Fatal("Bodies should only be logged in debug mode")
+	}
+
+	buf.Reset()
+	r.EnableDebug()
+	if _, e := r.GetAccount("test21"); e != nil {
+		t.Fatal(e.Error())
+	}
+	if !strings.Contains(buf.String(), "recurly response body") {
+		t.Fatalf("Expected the response body in debug mode: %s", buf.String())
+	}
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret-cookie")
+		fmt.Fprintf(w, "%s", accountGet)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	r := NewClient("", WithBaseURL(ts.URL), WithLogger(logger))
+	r.EnableDebug()
+	acc := r.NewAccount()
+	acc.AccountCode = "test21"
+	acc.B = new(BillingInfo)
+	acc.B.Number = "4111111111111111"
+	acc.B.VerificationValue = "123"
+	if e := acc.Create(); e != nil {
+		t.Fatal(e.Error())
+	}
+	out := buf.String()
+	for _, secret := range []string{"4111111111111111", "<verification_value>123", "1781d57cd7cfacc216314349e286ff4a", "secret-cookie"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("%s was not redacted: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "<number>[FILTERED]</number>") {
+		t.Fatalf("Expected the card number to be masked: %s", out)
+	}
+
+	r = NewClient("", WithRedactedFields("email"))
+	if got := r.Redact([]byte(`<email>a@example.com</email><number>4111</number>`)); got != `<email>[FILTERED]</email><number>4111</number>` {
+		t.Fatalf("Unexpected redaction %s", got)
+	}
+}
@@ -0,0 +1,129 @@
+package gorecurly
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+//Sends a request and returns its response, the function form of http.RoundTripper
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+//A Middleware wraps the sending of every request attempt.  It can change the
+//request, inspect the response, or return an error without calling next.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+//Wrap every request in middleware.  The first middleware is the outermost,
+//options can be repeated and add to the chain.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(r *Recurly) {
+		r.middleware = append(r.middleware, middleware...)
+	}
+}
+
+//A Middleware calling fn before every request is sent, e.g. to add headers.
+//An error from fn is returned instead of sending the request.
+func BeforeRequest(fn func(*http.Request) error) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := fn(req); err != nil {
+				return nil, err
+			}
+			return next(req)
+		}
+	}
+}
+
+//A Middleware calling fn with the outcome of every request, e.g. for metrics.
+//resp is nil when err is not.  fn must not consume the response body.
+func AfterResponse(fn func(req *http.Request, resp *http.Response, err error)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			fn(req, resp, err)
+			return resp, err
+		}
+	}
+}
+
+//Describes the api call a request belongs to
+type RequestInfo struct {
+	//The resource type, one of ACCOUNTS, SUBSCRIPTIONS, BILLINGINFO...
+	Resource string
+	//One of get, list, create, update or delete
+	Operation string
+	//The http method and the endpoint relative to the api url, e.g. accounts/code/billing_info
+	Method   string
+	Endpoint string
+	//0 for the first attempt, counting up for retries
+	Attempt int
+}
+
+type requestInfoKey struct{}
+type operationKey struct{}
+
+//Return the RequestInfo of a request's context, available to middleware with req.Context()
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+//Name the operation of the requests made with ctx
+func withOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+//Describe the request made to endpoint with method
+func newRequestInfo(ctx context.Context, method, endpoint string) RequestInfo {
+	info := RequestInfo{Method: method, Endpoint: endpoint, Resource: resourceOf(endpoint)}
+	if op, ok := ctx.Value(operationKey{}).(string); ok {
+		info.Operation = op
+	} else {
+		switch method {
+		case "POST":
+			info.Operation = "create"
+		case "PUT":
+			info.Operation = "update"
+		case "DELETE":
+			info.Operation = "delete"
+		default:
+			info.Operation = "get"
+		}
+	}
+	return info
+}
+
+var resourceNames = map[string]bool{
+	ACCOUNTS:          true,
+	ADJUSTMENTS:       true,
+	BILLINGINFO:       true,
+	COUPONS:           true,
+	COUPONREDEMPTIONS: true,
+	INVOICES:          true,
+	PLANS:             true,
+	PLANADDONS:        true,
+	SUBSCRIPTIONS:     true,
+	TRANSACTIONS:      true,
+}
+
+//Return the innermost resource of an endpoint, accounts/code/billing_info is BILLINGINFO
+func resourceOf(endpoint string) string {
+	path := strings.SplitN(endpoint, "?", 2)[0]
+	parts := strings.Split(path, "/")
+	resource := parts[0]
+	for _, part := range parts[1:] {
+		if resourceNames[part] {
+			resource = part
+		}
+	}
+	return resource
+}
+
+//Build the middleware chain around the http client
+func (r *Recurly) roundTrip() RoundTripFunc {
+	rt := RoundTripFunc(r.client.Do)
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		rt = r.middleware[i](rt)
+	}
+	return rt
+}
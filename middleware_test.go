@@ -0,0 +1,115 @@
+package gorecurly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace") != "abc" {
+			w.WriteHeader(400)
+			return
+		}
+		w.Header().Set("X-Records", "1")
+		w.Header().Set("Link", `<https://api.recurly.com/v2/accounts?cursor=1234>; rel="next"`)
+		if r.URL.Path == "/accounts" {
+			fmt.Fprintf(w, "<accounts>%s</accounts>", accountCreate[strings.Index(accountCreate, "<account "):])
+			return
+		}
+		fmt.Fprintf(w, "%s", accountGet)
+	}))
+	defer ts.Close()
+
+	var seen []RequestInfo
+	var statuses []int
+	addHeader := BeforeRequest(func(req *http.Request) error {
+		req.Header.Set("X-Trace", "abc")
+		return nil
+	})
+	record := AfterResponse(func(req *http.Request, resp *http.Response, err error) {
+		info, _ := RequestInfoFromContext(req.Context())
+		seen = append(seen, info)
+		statuses = append(statuses, resp.StatusCode)
+	})
+	r := NewClient("", WithBaseURL(ts.URL), WithMiddleware(addHeader, record))
+	//the body is an account, only the request matters here
+	r.GetBillingInfo("test21")
+	if _, e := r.GetAccounts(); e != nil {
+		t.Fatal(e.Error())
+	}
+	if len(seen) != 2 || seen[0].Resource != BILLINGINFO || seen[0].Operation != "get" || seen[1].Resource != ACCOUNTS || seen[1].Operation != "list" {
+		t.Fatalf("Unexpected request info %+v", seen)
+	}
+	if statuses[1] != 200 {
+		t.Fatalf("Expected the header to be injected, got status %v", statuses[1])
+	}
+
+	open := errors.New("circuit open")
+	breaker := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, open
+		}
+	}
+	r = NewClient("", WithBaseURL(ts.URL), WithMiddleware(breaker))
+	if _, e := r.GetAccount("test21"); !errors.Is(e, open) {
+		t.Fatalf("Expected the middleware error, got %v", e)
+	}
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+type testSpan struct {
+	info      RequestInfo
+	status    int
+	requestID string
+	err       error
+	ended     bool
+}
+
+func (t *testTracer) Start(ctx context.Context, info RequestInfo) (context.Context, Span) {
+	s := &testSpan{info: info}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func (s *testSpan) SetResponse(status int, requestID string) {
+	s.status = status
+	s.requestID = requestID
+}
+
+func (s *testSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+func TestTracer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(404)
+	}))
+	defer ts.Close()
+
+	tracer := &testTracer{}
+	r := NewClient("", WithBaseURL(ts.URL), WithTracer(tracer))
+	sub := r.NewSubscription()
+	sub.UUID = "abc"
+	e := sub.Cancel()
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expected a single span for the call, got %v", len(tracer.spans))
+	}
+	s := tracer.spans[0]
+	if s.info.Resource != SUBSCRIPTIONS || s.info.Operation != "update" || s.status != 404 || s.requestID != "req-1" {
+		t.Fatalf("Unexpected span %+v", s)
+	}
+	if !s.ended || s.err != e || !errors.Is(s.err, ErrNotFound) {
+		t.Fatalf("Expected the span to end with the call's error, got %v", s.err)
+	}
+}
@@ -0,0 +1,474 @@
+package gorecurly
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//A notification recorded by a NotificationStore
+type StoredNotification struct {
+	//From NotificationID, the same for every delivery of a notification
+	ID         string
+	Type       string
+	Body       []byte
+	ReceivedAt time.Time
+	//Zero until a delivery, retry or replay was handled without error
+	ProcessedAt time.Time
+	Attempts    int
+	LastError   string
+	//Set while a delivery, retry or replay dispatches the notification,
+	//a duplicate arriving before then is acknowledged without dispatching it
+	ClaimedUntil time.Time
+}
+
+//Records the notifications received by a NotificationHandler, for deduplication and replay.
+//Implementations must be safe for concurrent use.
+type NotificationStore interface {
+	//Store n unless a notification with its ID is stored, return the stored one and whether n was added
+	Save(ctx context.Context, n StoredNotification) (StoredNotification, bool, error)
+	//Set ClaimedUntil of the stored notification with id to until, unless it is claimed until
+	//after now.  Return the stored notification and whether it was claimed, atomically.
+	Claim(ctx context.Context, id string, until time.Time) (StoredNotification, bool, error)
+	//Replace the stored notification with the same ID
+	Update(ctx context.Context, n StoredNotification) error
+	//Return the notifications received from from until to, oldest first
+	Range(ctx context.Context, from, to time.Time) ([]StoredNotification, error)
+}
+
+//How long a delivery holds its claim on a notification unless set with WithNotificationLease
+const defaultNotificationLease = 5 * time.Minute
+
+//Identify a notification by its body.  Recurly sends the same body again when it retries
+//a notification, and the body carries the resource ids and timestamps of the event.
+func NotificationID(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+//Record notifications in store.  A notification that was handled, or is being handled,
+//is answered without calling the callbacks.
+func WithNotificationStore(store NotificationStore) NotificationOption {
+	return func(h *NotificationHandler) error {
+		h.store = store
+		return nil
+	}
+}
+
+//Retry failing callbacks in the background up to retries times, waiting backoff, then twice
+//as long and so on.  The notification is acknowledged once the first attempt failed, since
+//it is kept in the store; a notification that still fails afterwards is left for Replay.
+//Needs WithNotificationStore.
+func WithNotificationRetries(retries int, backoff time.Duration) NotificationOption {
+	return func(h *NotificationHandler) error {
+		h.retries = retries
+		h.backoff = backoff
+		return nil
+	}
+}
+
+//How long a delivery may dispatch a notification before a duplicate may dispatch it again,
+//in case the process handling it died.  Longer than any callback should take, 5 minutes by default.
+func WithNotificationLease(lease time.Duration) NotificationOption {
+	return func(h *NotificationHandler) error {
+		if lease <= 0 {
+			return errors.New("The notification lease must be positive")
+		}
+		h.lease = lease
+		return nil
+	}
+}
+
+//Dispatch the stored notifications received from from until to again, e.g. after fixing a callback.
+//Every notification is replayed once, including the ones that were handled, except the ones
+//being dispatched right now.  The errors are joined.
+func (h *NotificationHandler) Replay(ctx context.Context, from, to time.Time) error {
+	if h.store == nil {
+		return errors.New("No notification store, set it with WithNotificationStore")
+	}
+	stored, err := h.store.Range(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range stored {
+		n, err := h.r.ParseNotification(s.Body)
+		if err == nil {
+			err = h.replay(ctx, s.ID, n)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *NotificationHandler) replay(ctx context.Context, id string, n Notification) error {
+	stored, claimed, err := h.store.Claim(ctx, id, time.Now().Add(h.lease))
+	if err != nil || !claimed {
+		return err
+	}
+	_, err = h.attempt(ctx, stored, n, 0)
+	return err
+}
+
+//Wait for the background retries to finish.  When ctx is done first the pending retries
+//are canceled, their notifications are left for Replay.  Failing callbacks are not retried
+//in the background afterwards, they answer with a 500 instead.
+func (h *NotificationHandler) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.stopped = true
+	h.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		h.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		h.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+//Record and dispatch a notification that was just received
+func (h *NotificationHandler) process(ctx context.Context, body []byte, n Notification) error {
+	if h.store == nil {
+		return h.Dispatch(ctx, n)
+	}
+	stored, _, err := h.store.Save(ctx, StoredNotification{
+		ID:         NotificationID(body),
+		Type:       n.Type(),
+		Body:       body,
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if !stored.ProcessedAt.IsZero() {
+		//a retry of a notification that was handled
+		return nil
+	}
+	stored, claimed, err := h.store.Claim(ctx, stored.ID, time.Now().Add(h.lease))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		//another delivery is dispatching it and answers for it
+		return nil
+	}
+	if !stored.ProcessedAt.IsZero() {
+		stored.ClaimedUntil = time.Time{}
+		return h.store.Update(ctx, stored)
+	}
+	var hold time.Duration
+	if h.retries > 0 {
+		hold = h.lease + h.backoff
+	}
+	stored, err = h.attempt(ctx, stored, n, hold)
+	if err == nil || hold == 0 {
+		return err
+	}
+	if !h.retryLater(stored, n) {
+		//shut down, release the claim and let Recurly send it again
+		stored.ClaimedUntil = time.Time{}
+		h.store.Update(context.WithoutCancel(ctx), stored)
+		return err
+	}
+	h.r.log(ctx, slog.LevelWarn, "recurly: notification handler failed, retrying", slog.String("type", n.Type()), slog.Any("error", err))
+	return nil
+}
+
+//Dispatch a claimed notification once and record the outcome.  After a failure the claim
+//is held for hold, so a retry can follow, otherwise it is released.
+func (h *NotificationHandler) attempt(ctx context.Context, stored StoredNotification, n Notification, hold time.Duration) (StoredNotification, error) {
+	err := h.Dispatch(ctx, n)
+	stored.Attempts++
+	stored.ClaimedUntil = time.Time{}
+	if err != nil {
+		stored.LastError = err.Error()
+		if hold > 0 {
+			stored.ClaimedUntil = time.Now().Add(hold)
+		}
+	} else {
+		stored.ProcessedAt = time.Now()
+		stored.LastError = ""
+	}
+	if uerr := h.store.Update(context.WithoutCancel(ctx), stored); uerr != nil && err == nil {
+		return stored, uerr
+	}
+	return stored, err
+}
+
+//Retry a failed notification in the background while holding its claim,
+//returns false when the handler is shut down
+func (h *NotificationHandler) retryLater(stored StoredNotification, n Notification) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopped {
+		return false
+	}
+	h.pending.Add(1)
+	go func() {
+		defer h.pending.Done()
+		ctx := h.background
+		for attempt := 0; attempt < h.retries; attempt++ {
+			if sleepCtx(ctx, h.backoff<<uint(attempt)) != nil {
+				//release the claim, the error stays recorded for Replay
+				stored.ClaimedUntil = time.Time{}
+				h.store.Update(context.WithoutCancel(ctx), stored)
+				return
+			}
+			var hold time.Duration
+			if attempt+1 < h.retries {
+				hold = h.lease + h.backoff<<uint(attempt+1)
+			}
+			var err error
+			if stored, err = h.attempt(ctx, stored, n, hold); err == nil {
+				return
+			}
+			h.r.log(ctx, slog.LevelWarn, "recurly: notification retry failed", slog.String("type", n.Type()), slog.Int("attempts", stored.Attempts), slog.Any("error", err))
+		}
+	}()
+	return true
+}
+
+//A NotificationStore in memory, for tests and single process deployments
+type MemoryNotificationStore struct {
+	mu            sync.Mutex
+	notifications map[string]StoredNotification
+}
+
+//Create an empty in memory store
+func NewMemoryNotificationStore() *MemoryNotificationStore {
+	return &MemoryNotificationStore{notifications: map[string]StoredNotification{}}
+}
+
+//Store n unless a notification with its ID is stored
+func (s *MemoryNotificationStore) Save(ctx context.Context, n StoredNotification) (StoredNotification, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.notifications[n.ID]; ok {
+		return existing, false, nil
+	}
+	s.notifications[n.ID] = n
+	return n, true, nil
+}
+
+//Claim the stored notification with id until until, unless it is claimed until after now
+func (s *MemoryNotificationStore) Claim(ctx context.Context, id string, until time.Time) (StoredNotification, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.notifications[id]
+	if !ok {
+		return n, false, os.ErrNotExist
+	}
+	if n.ClaimedUntil.After(time.Now()) {
+		return n, false, nil
+	}
+	n.ClaimedUntil = until
+	s.notifications[id] = n
+	return n, true, nil
+}
+
+//Replace the stored notification with the same ID
+func (s *MemoryNotificationStore) Update(ctx context.Context, n StoredNotification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications[n.ID] = n
+	return nil
+}
+
+//Return the notifications received from from until to, oldest first
+func (s *MemoryNotificationStore) Range(ctx context.Context, from, to time.Time) ([]StoredNotification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var found []StoredNotification
+	for _, n := range s.notifications {
+		if !n.ReceivedAt.Before(from) && !n.ReceivedAt.After(to) {
+			found = append(found, n)
+		}
+	}
+	sortNotifications(found)
+	return found, nil
+}
+
+//A NotificationStore that keeps one json file per notification in a directory.
+//Files are written completely before they are linked or renamed into place, and claims
+//take a short lived lock file, so processes sharing the directory dedupe against each other.
+type FileNotificationStore struct {
+	dir string
+}
+
+//A lock file older than this was left behind by a process that died holding it
+const staleNotificationLock = 30 * time.Second
+
+//Create a store in dir, the directory is created if needed
+func NewFileNotificationStore(dir string) (*FileNotificationStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileNotificationStore{dir: dir}, nil
+}
+
+func (s *FileNotificationStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+//Store n unless a notification with its ID is stored
+func (s *FileNotificationStore) Save(ctx context.Context, n StoredNotification) (StoredNotification, bool, error) {
+	tmp, err := s.writeTemp(n)
+	if err != nil {
+		return n, false, err
+	}
+	defer os.Remove(tmp)
+	//linking fails if the file exists, so only one delivery adds it
+	if err := os.Link(tmp, s.path(n.ID)); os.IsExist(err) {
+		existing, err := s.read(s.path(n.ID))
+		return existing, false, err
+	} else if err != nil {
+		return n, false, err
+	}
+	return n, true, nil
+}
+
+//Claim the stored notification with id until until, unless it is claimed until after now
+func (s *FileNotificationStore) Claim(ctx context.Context, id string, until time.Time) (StoredNotification, bool, error) {
+	unlock, err := s.lock(ctx, id)
+	if err != nil {
+		return StoredNotification{}, false, err
+	}
+	defer unlock()
+	n, err := s.read(s.path(id))
+	if err != nil {
+		return n, false, err
+	}
+	if n.ClaimedUntil.After(time.Now()) {
+		return n, false, nil
+	}
+	n.ClaimedUntil = until
+	return n, true, s.replace(n)
+}
+
+//Replace the stored notification with the same ID
+func (s *FileNotificationStore) Update(ctx context.Context, n StoredNotification) error {
+	unlock, err := s.lock(ctx, n.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return s.replace(n)
+}
+
+//Write n to a temporary file and rename it over the stored one
+func (s *FileNotificationStore) replace(n StoredNotification) error {
+	tmp, err := s.writeTemp(n)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path(n.ID)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+//Write n to a new temporary file in the directory and return its path
+func (s *FileNotificationStore) writeTemp(n StoredNotification) (string, error) {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempFile(s.dir, n.ID+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+//Lock the notification with id for a read and write of its file, against other
+//goroutines and processes.  The returned function unlocks it.
+func (s *FileNotificationStore) lock(ctx context.Context, id string) (func(), error) {
+	path := filepath.Join(s.dir, id+".lock")
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleNotificationLock {
+			os.Remove(path)
+			continue
+		}
+		if err := sleepCtx(ctx, 5*time.Millisecond); err != nil {
+			return nil, err
+		}
+	}
+}
+
+//Return the notifications received from from until to, oldest first
+func (s *FileNotificationStore) Range(ctx context.Context, from, to time.Time) ([]StoredNotification, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var found []StoredNotification
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		n, err := s.read(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if !n.ReceivedAt.Before(from) && !n.ReceivedAt.After(to) {
+			found = append(found, n)
+		}
+	}
+	sortNotifications(found)
+	return found, nil
+}
+
+func (s *FileNotificationStore) read(path string) (n StoredNotification, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return n, err
+	}
+	err = json.Unmarshal(data, &n)
+	return n, err
+}
+
+//Sort by the time received, oldest first
+func sortNotifications(n []StoredNotification) {
+	sort.SliceStable(n, func(i, j int) bool {
+		return n[i].ReceivedAt.Before(n[j].ReceivedAt)
+	})
+}
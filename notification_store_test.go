@@ -0,0 +1,192 @@
+package gorecurly
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNotificationStore(t *testing.T) {
+	file, err := NewFileNotificationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, store := range []NotificationStore{NewMemoryNotificationStore(), file} {
+		r := NewClient("")
+		h, err := r.NewNotificationHandler(WithInsecureNoAuth(), WithNotificationStore(store), WithNotificationRetries(1, time.Millisecond))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		var mu sync.Mutex
+		calls := map[string]int{}
+		broken := true
+		h.OnFailedPayment(func(ctx context.Context, n *FailedPaymentNotification) error {
+			mu.Lock()
+			defer mu.Unlock()
+			code := n.Account.AccountCode
+			if calls[code]++; code == "flaky" && calls[code] == 1 || code == "broken" && broken {
+				return errors.New("provisioning is down")
+			}
+			return nil
+		})
+		post := func(body string) int {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+			return w.Code
+		}
+		start := time.Now()
+		//failures are acknowledged and retried in the background
+		flaky := strings.Replace(failedPaymentNotification, "<account_code>1<", "<account_code>flaky<", 1)
+		brokenBody := strings.Replace(failedPaymentNotification, "<account_code>1<", "<account_code>broken<", 1)
+		if code := post(flaky); code != 200 {
+			t.Fatalf("%T: expected the failure to be acknowledged, got %v", store, code)
+		}
+		if code := post(brokenBody); code != 200 {
+			t.Fatalf("%T: expected the failure to be acknowledged, got %v", store, code)
+		}
+		if err := h.Shutdown(context.Background()); err != nil {
+			t.Fatal(err.Error())
+		}
+		if calls["flaky"] != 2 || calls["broken"] != 2 {
+			t.Fatalf("%T: expected the callbacks to be retried once, got %v", store, calls)
+		}
+		if code := post(flaky); code != 200 || calls["flaky"] != 2 {
+			t.Fatalf("%T: expected the duplicate to be skipped, got %v after %v calls", store, code, calls["flaky"])
+		}
+
+		stored, err := store.Range(context.Background(), start, time.Now())
+		if err != nil || len(stored) != 2 {
+			t.Fatalf("%T: unexpected stored notifications %v %v", store, stored, err)
+		}
+		if stored[0].Attempts != 2 || stored[0].ProcessedAt.IsZero() || stored[1].LastError != "provisioning is down" || !stored[1].ProcessedAt.IsZero() {
+			t.Fatalf("%T: unexpected outcomes %+v", store, stored)
+		}
+		if !stored[0].ClaimedUntil.IsZero() || !stored[1].ClaimedUntil.IsZero() {
+			t.Fatalf("%T: expected the claims to be released, got %+v", store, stored)
+		}
+		//after shutdown failures are reported to Recurly
+		if code := post(brokenBody); code != 500 || calls["broken"] != 3 {
+			t.Fatalf("%T: expected the failure to be reported, got %v after %v calls", store, code, calls["broken"])
+		}
+
+		broken = false
+		if err := h.Replay(context.Background(), start, time.Now()); err != nil {
+			t.Fatalf("%T: replay failed %v", store, err)
+		}
+		if calls["flaky"] != 3 || calls["broken"] != 4 {
+			t.Fatalf("%T: expected every notification to be replayed, got %v", store, calls)
+		}
+		stored, _ = store.Range(context.Background(), start, time.Now())
+		if stored[1].ProcessedAt.IsZero() || stored[1].LastError != "" || stored[1].Type != "failed_payment_notification" {
+			t.Fatalf("%T: expected the replay to be recorded, got %+v", store, stored[1])
+		}
+		if code := post(brokenBody); code != 200 || calls["broken"] != 4 {
+			t.Fatalf("%T: expected the replayed notification to be skipped, got %v", store, code)
+		}
+	}
+	h, _ := NewClient("").NewNotificationHandler(WithInsecureNoAuth())
+	if err := h.Replay(context.Background(), time.Time{}, time.Now()); err == nil {
+		t.Fatal("Expected replay without a store to fail")
+	}
+	if _, err := NewClient("").NewNotificationHandler(WithInsecureNoAuth(), WithNotificationRetries(1, time.Second)); err == nil {
+		t.Fatal("Expected retries without a store to be refused")
+	}
+}
+
+func TestNotificationInFlight(t *testing.T) {
+	file, err := NewFileNotificationStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, store := range []NotificationStore{NewMemoryNotificationStore(), file} {
+		h, _ := NewClient("").NewNotificationHandler(WithInsecureNoAuth(), WithNotificationStore(store))
+		started, release := make(chan struct{}), make(chan struct{})
+		calls := 0
+		h.OnFailedPayment(func(ctx context.Context, n *FailedPaymentNotification) error {
+			if calls++; calls == 1 {
+				close(started)
+				<-release
+			}
+			return nil
+		})
+		post := func() int {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(failedPaymentNotification)))
+			return w.Code
+		}
+		first := make(chan int)
+		go func() { first <- post() }()
+		<-started
+		//a redelivery while the first delivery is still running
+		if code := post(); code != 200 || calls != 1 {
+			t.Fatalf("%T: expected the in flight duplicate to be acknowledged without dispatching, got %v after %v calls", store, code, calls)
+		}
+		close(release)
+		if code := <-first; code != 200 {
+			t.Fatalf("%T: expected the first delivery to succeed, got %v", store, code)
+		}
+		if code := post(); code != 200 || calls != 1 {
+			t.Fatalf("%T: expected the duplicate to be skipped, got %v after %v calls", store, code, calls)
+		}
+	}
+}
+
+func TestFileNotificationStoreConcurrentSave(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileNotificationStore(dir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	n := StoredNotification{ID: "abc", Type: "new_account_notification", Body: []byte(strings.Repeat("x", 1<<16)), ReceivedAt: time.Now()}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stored, ok, err := store.Save(context.Background(), n)
+			if err != nil || len(stored.Body) != len(n.Body) {
+				t.Errorf("Unexpected save %v %v", len(stored.Body), err)
+			}
+			mu.Lock()
+			if ok {
+				created++
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if created != 1 {
+		t.Fatalf("Expected one save to add the notification, got %v", created)
+	}
+	claims := 0
+	for i := 0; i < 5; i++ {
+		if _, ok, err := store.Claim(context.Background(), "abc", time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err.Error())
+		} else if ok {
+			claims++
+		}
+	}
+	if claims != 1 {
+		t.Fatalf("Expected one claim, got %v", claims)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "abc.json" {
+		t.Fatalf("Expected only the notification file to be left, got %v", entries)
+	}
+	//a lock left behind by a crashed process
+	lock := filepath.Join(dir, "abc.lock")
+	os.WriteFile(lock, nil, 0600)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(lock, old, old)
+	if err := store.Update(context.Background(), n); err != nil {
+		t.Fatal(err.Error())
+	}
+}
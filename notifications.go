@@ -0,0 +1,450 @@
+package gorecurly
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//A push notification decoded by ParseNotification, one of the *Notification types of this package
+type Notification interface {
+	//The name of the notification, e.g. new_subscription_notification
+	Type() string
+}
+
+//The name of the root element, shared by every notification
+type notificationType struct {
+	name string
+}
+
+//The name of the notification, e.g. new_subscription_notification
+func (n notificationType) Type() string {
+	return n.name
+}
+
+func (n *notificationType) setType(name string) {
+	n.name = name
+}
+
+//A transaction as it is sent in payment notifications, which have an id instead of the uuid
+type NotificationTransaction struct {
+	Transaction
+	ID             string      `xml:"id,omitempty"`
+	InvoiceID      string      `xml:"invoice_id,omitempty"`
+	InvoiceNumber  string      `xml:"invoice_number,omitempty"`
+	SubscriptionID string      `xml:"subscription_id,omitempty"`
+	Date           RecurlyDate `xml:"date,omitempty"`
+	Message        string      `xml:"message,omitempty"`
+}
+
+//Body of the notifications about an account
+type AccountNotification struct {
+	notificationType
+	Account Account `xml:"account"`
+}
+
+func (n *AccountNotification) attach(r *Recurly) {
+	n.Account.attach(r)
+}
+
+//Body of the notifications about a subscription
+type SubscriptionNotification struct {
+	notificationType
+	Account      Account      `xml:"account"`
+	Subscription Subscription `xml:"subscription"`
+}
+
+func (n *SubscriptionNotification) attach(r *Recurly) {
+	n.Account.attach(r)
+	n.Subscription.attach(r)
+}
+
+//Body of the notifications about a payment
+type PaymentNotification struct {
+	notificationType
+	Account     Account                 `xml:"account"`
+	Transaction NotificationTransaction `xml:"transaction"`
+}
+
+func (n *PaymentNotification) attach(r *Recurly) {
+	n.Account.attach(r)
+	n.Transaction.attach(r)
+	if n.Transaction.UUID == "" {
+		n.Transaction.UUID = n.Transaction.ID
+	}
+}
+
+//Body of the notifications about an invoice
+type InvoiceNotification struct {
+	notificationType
+	Account Account `xml:"account"`
+	Invoice Invoice `xml:"invoice"`
+}
+
+func (n *InvoiceNotification) attach(r *Recurly) {
+	n.Account.attach(r)
+	n.Invoice.attach(r)
+}
+
+//The push notifications Recurly sends, each is named after its root element
+type NewAccountNotification struct{ AccountNotification }
+type CanceledAccountNotification struct{ AccountNotification }
+type BillingInfoUpdatedNotification struct{ AccountNotification }
+type ReactivatedAccountNotification struct{ SubscriptionNotification }
+type NewSubscriptionNotification struct{ SubscriptionNotification }
+type UpdatedSubscriptionNotification struct{ SubscriptionNotification }
+type CanceledSubscriptionNotification struct{ SubscriptionNotification }
+type ExpiredSubscriptionNotification struct{ SubscriptionNotification }
+type RenewedSubscriptionNotification struct{ SubscriptionNotification }
+type SuccessfulPaymentNotification struct{ PaymentNotification }
+type FailedPaymentNotification struct{ PaymentNotification }
+type SuccessfulRefundNotification struct{ PaymentNotification }
+type VoidPaymentNotification struct{ PaymentNotification }
+type NewInvoiceNotification struct{ InvoiceNotification }
+type ProcessingInvoiceNotification struct{ InvoiceNotification }
+type ClosedInvoiceNotification struct{ InvoiceNotification }
+type PastDueInvoiceNotification struct{ InvoiceNotification }
+
+//A notification this package has no type for, Body is the raw xml
+type UnknownNotification struct {
+	notificationType
+	Body []byte
+}
+
+var notificationTypes = map[string]func() Notification{
+	"new_account_notification":           func() Notification { return &NewAccountNotification{} },
+	"canceled_account_notification":      func() Notification { return &CanceledAccountNotification{} },
+	"billing_info_updated_notification":  func() Notification { return &BillingInfoUpdatedNotification{} },
+	"reactivated_account_notification":   func() Notification { return &ReactivatedAccountNotification{} },
+	"new_subscription_notification":      func() Notification { return &NewSubscriptionNotification{} },
+	"updated_subscription_notification":  func() Notification { return &UpdatedSubscriptionNotification{} },
+	"canceled_subscription_notification": func() Notification { return &CanceledSubscriptionNotification{} },
+	"expired_subscription_notification":  func() Notification { return &ExpiredSubscriptionNotification{} },
+	"renewed_subscription_notification":  func() Notification { return &RenewedSubscriptionNotification{} },
+	"successful_payment_notification":    func() Notification { return &SuccessfulPaymentNotification{} },
+	"failed_payment_notification":        func() Notification { return &FailedPaymentNotification{} },
+	"successful_refund_notification":     func() Notification { return &SuccessfulRefundNotification{} },
+	"void_payment_notification":          func() Notification { return &VoidPaymentNotification{} },
+	"new_invoice_notification":           func() Notification { return &NewInvoiceNotification{} },
+	"processing_invoice_notification":    func() Notification { return &ProcessingInvoiceNotification{} },
+	"closed_invoice_notification":        func() Notification { return &ClosedInvoiceNotification{} },
+	"past_due_invoice_notification":      func() Notification { return &PastDueInvoiceNotification{} },
+}
+
+//Decode a push notification into its type, the resources it carries are bound to the client
+func (r *Recurly) ParseNotification(body []byte) (Notification, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(body, &root); err != nil {
+		return nil, err
+	}
+	newNotification, ok := notificationTypes[root.XMLName.Local]
+	if !ok {
+		return &UnknownNotification{notificationType{root.XMLName.Local}, body}, nil
+	}
+	n := newNotification()
+	if err := xml.Unmarshal(body, n); err != nil {
+		return nil, err
+	}
+	n.(interface{ setType(string) }).setType(root.XMLName.Local)
+	if a, ok := n.(attacher); ok {
+		a.attach(r)
+	}
+	return n, nil
+}
+
+//An http.Handler for Recurly push notifications.  Register the callbacks before serving,
+//a callback that returns an error answers with a 500 so Recurly sends the notification again,
+//unless WithNotificationRetries retries it in the background.
+type NotificationHandler struct {
+	r            *Recurly
+	username     string
+	password     string
+	allowed      []*net.IPNet
+	forwardedFor bool
+	insecure     bool
+	callbacks    map[string][]func(context.Context, Notification) error
+	all          []func(context.Context, Notification) error
+	store        NotificationStore
+	retries      int
+	backoff      time.Duration
+	lease        time.Duration
+	//Background retries run with background until Shutdown cancels it
+	background context.Context
+	cancel     context.CancelFunc
+	pending    sync.WaitGroup
+	mu         sync.Mutex
+	stopped    bool
+}
+
+//Returned by NewNotificationHandler when neither basic auth nor an allowlist is configured
+var ErrNotificationNoAuth = errors.New("A notification handler needs WithNotificationAuth or WithNotificationIPs, use WithInsecureNoAuth to accept notifications from anyone.")
+
+//Options for NewNotificationHandler
+type NotificationOption func(*NotificationHandler) error
+
+//Require the basic auth credentials set for the push notification url in Recurly
+func WithNotificationAuth(username, password string) NotificationOption {
+	return func(h *NotificationHandler) error {
+		if username == "" {
+			return errors.New("The notification username must not be empty")
+		}
+		h.username = username
+		h.password = password
+		return nil
+	}
+}
+
+//Only accept notifications from these addresses or CIDR ranges
+func WithNotificationIPs(ips ...string) NotificationOption {
+	return func(h *NotificationHandler) error {
+		for _, ip := range ips {
+			if !strings.Contains(ip, "/") {
+				if strings.Contains(ip, ":") {
+					ip += "/128"
+				} else {
+					ip += "/32"
+				}
+			}
+			_, network, err := net.ParseCIDR(ip)
+			if err != nil {
+				return err
+			}
+			h.allowed = append(h.allowed, network)
+		}
+		return nil
+	}
+}
+
+//Check the allowlist against the last X-Forwarded-For address, which was added by the proxy in front of the handler
+func WithForwardedFor() NotificationOption {
+	return func(h *NotificationHandler) error {
+		h.forwardedFor = true
+		return nil
+	}
+}
+
+//Accept notifications without basic auth or an allowlist, e.g. when a proxy in front of the handler authenticates them
+func WithInsecureNoAuth() NotificationOption {
+	return func(h *NotificationHandler) error {
+		h.insecure = true
+		return nil
+	}
+}
+
+//Create a handler for push notifications, decoded resources are bound to the client.
+//Returns ErrNotificationNoAuth unless basic auth or an allowlist is configured.
+func (r *Recurly) NewNotificationHandler(opts ...NotificationOption) (*NotificationHandler, error) {
+	h := &NotificationHandler{r: r, callbacks: map[string][]func(context.Context, Notification) error{}, lease: defaultNotificationLease}
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	if h.username == "" && len(h.allowed) == 0 && !h.insecure {
+		return nil, ErrNotificationNoAuth
+	}
+	if h.retries > 0 && h.store == nil {
+		return nil, errors.New("WithNotificationRetries needs WithNotificationStore")
+	}
+	h.background, h.cancel = context.WithCancel(context.Background())
+	return h, nil
+}
+
+//Authenticate, decode and dispatch a push notification
+func (h *NotificationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.allowedIP(req) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if !h.authorized(req) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="recurly"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	//notifications are a few kilobytes
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "could not read notification", http.StatusBadRequest)
+		return
+	}
+	n, err := h.r.ParseNotification(body)
+	if err != nil {
+		h.r.log(req.Context(), slog.LevelWarn, "recurly: could not decode notification", slog.Any("error", err))
+		http.Error(w, "could not decode notification", http.StatusBadRequest)
+		return
+	}
+	if err := h.process(req.Context(), body, n); err != nil {
+		h.r.log(req.Context(), slog.LevelWarn, "recurly: notification handler failed", slog.String("type", n.Type()), slog.Any("error", err))
+		http.Error(w, "notification handler failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+//Call the callbacks registered for n, stopping at the first error
+func (h *NotificationHandler) Dispatch(ctx context.Context, n Notification) error {
+	for _, fn := range h.callbacks[n.Type()] {
+		if err := fn(ctx, n); err != nil {
+			return err
+		}
+	}
+	for _, fn := range h.all {
+		if err := fn(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Check the remote address against the allowlist, no allowlist accepts every address
+func (h *NotificationHandler) allowedIP(req *http.Request) bool {
+	if len(h.allowed) == 0 {
+		return true
+	}
+	addr := req.RemoteAddr
+	if forwarded := req.Header.Values("X-Forwarded-For"); h.forwardedFor && len(forwarded) > 0 {
+		hops := strings.Split(forwarded[len(forwarded)-1], ",")
+		addr = strings.TrimSpace(hops[len(hops)-1])
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range h.allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+//Check the basic auth credentials, no username accepts every request
+func (h *NotificationHandler) authorized(req *http.Request) bool {
+	if h.username == "" {
+		return true
+	}
+	username, password, ok := req.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(username), []byte(h.username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) == 1
+}
+
+//Register fn for the notifications named name
+func on[N Notification](h *NotificationHandler, name string, fn func(context.Context, N) error) {
+	h.callbacks[name] = append(h.callbacks[name], func(ctx context.Context, n Notification) error {
+		typed, ok := n.(N)
+		if !ok {
+			return fmt.Errorf("Unexpected notification %T for %s", n, name)
+		}
+		return fn(ctx, typed)
+	})
+}
+
+//Called for every notification, including the ones without a type in this package
+func (h *NotificationHandler) OnNotification(fn func(context.Context, Notification) error) {
+	h.all = append(h.all, fn)
+}
+
+//Called for new_account_notification
+func (h *NotificationHandler) OnNewAccount(fn func(context.Context, *NewAccountNotification) error) {
+	on(h, "new_account_notification", fn)
+}
+
+//Called for canceled_account_notification
+func (h *NotificationHandler) OnCanceledAccount(fn func(context.Context, *CanceledAccountNotification) error) {
+	on(h, "canceled_account_notification", fn)
+}
+
+//Called for billing_info_updated_notification
+func (h *NotificationHandler) OnBillingInfoUpdated(fn func(context.Context, *BillingInfoUpdatedNotification) error) {
+	on(h, "billing_info_updated_notification", fn)
+}
+
+//Called for reactivated_account_notification
+func (h *NotificationHandler) OnReactivatedAccount(fn func(context.Context, *ReactivatedAccountNotification) error) {
+	on(h, "reactivated_account_notification", fn)
+}
+
+//Called for new_subscription_notification
+func (h *NotificationHandler) OnNewSubscription(fn func(context.Context, *NewSubscriptionNotification) error) {
+	on(h, "new_subscription_notification", fn)
+}
+
+//Called for updated_subscription_notification
+func (h *NotificationHandler) OnUpdatedSubscription(fn func(context.Context, *UpdatedSubscriptionNotification) error) {
+	on(h, "updated_subscription_notification", fn)
+}
+
+//Called for canceled_subscription_notification
+func (h *NotificationHandler) OnCanceledSubscription(fn func(context.Context, *CanceledSubscriptionNotification) error) {
+	on(h, "canceled_subscription_notification", fn)
+}
+
+//Called for expired_subscription_notification
+func (h *NotificationHandler) OnExpiredSubscription(fn func(context.Context, *ExpiredSubscriptionNotification) error) {
+	on(h, "expired_subscription_notification", fn)
+}
+
+//Called for renewed_subscription_notification
+func (h *NotificationHandler) OnRenewedSubscription(fn func(context.Context, *RenewedSubscriptionNotification) error) {
+	on(h, "renewed_subscription_notification", fn)
+}
+
+//Called for successful_payment_notification
+func (h *NotificationHandler) OnSuccessfulPayment(fn func(context.Context, *SuccessfulPaymentNotification) error) {
+	on(h, "successful_payment_notification", fn)
+}
+
+//Called for failed_payment_notification
+func (h *NotificationHandler) OnFailedPayment(fn func(context.Context, *FailedPaymentNotification) error) {
+	on(h, "failed_payment_notification", fn)
+}
+
+//Called for successful_refund_notification
+func (h *NotificationHandler) OnSuccessfulRefund(fn func(context.Context, *SuccessfulRefundNotification) error) {
+	on(h, "successful_refund_notification", fn)
+}
+
+//Called for void_payment_notification
+func (h *NotificationHandler) OnVoidPayment(fn func(context.Context, *VoidPaymentNotification) error) {
+	on(h, "void_payment_notification", fn)
+}
+
+//Called for new_invoice_notification
+func (h *NotificationHandler) OnNewInvoice(fn func(context.Context, *NewInvoiceNotification) error) {
+	on(h, "new_invoice_notification", fn)
+}
+
+//Called for processing_invoice_notification
+func (h *NotificationHandler) OnProcessingInvoice(fn func(context.Context, *ProcessingInvoiceNotification) error) {
+	on(h, "processing_invoice_notification", fn)
+}
+
+//Called for closed_invoice_notification
+func (h *NotificationHandler) OnClosedInvoice(fn func(context.Context, *ClosedInvoiceNotification) error) {
+	on(h, "closed_invoice_notification", fn)
+}
+
+//Called for past_due_invoice_notification
+func (h *NotificationHandler) OnPastDueInvoice(fn func(context.Context, *PastDueInvoiceNotification) error) {
+	on(h, "past_due_invoice_notification", fn)
+}
@@ -0,0 +1,156 @@
+package gorecurly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const newSubscriptionNotification = `<?xml version="1.0" encoding="UTF-8"?>
+<new_subscription_notification>
+  <account>
+    <account_code>1</account_code>
+    <username nil="true"></username>
+    <email>verena@example.com</email>
+    <first_name>Verena</first_name>
+    <last_name>Example</last_name>
+    <company_name nil="true"></company_name>
+  </account>
+  <subscription>
+    <plan>
+      <plan_code>bronze</plan_code>
+      <name>Bronze Plan</name>
+    </plan>
+    <uuid>8047cb4fd5f874b14d713d785436ebd3</uuid>
+    <state>active</state>
+    <quantity type="integer">2</quantity>
+    <total_amount_in_cents type="integer">17000</total_amount_in_cents>
+    <activated_at type="datetime">2009-11-22T13:10:38Z</activated_at>
+    <canceled_at type="datetime"></canceled_at>
+    <expires_at type="datetime"></expires_at>
+  </subscription>
+</new_subscription_notification>`
+
+const failedPaymentNotification = `<?xml version="1.0" encoding="UTF-8"?>
+<failed_payment_notification>
+  <account>
+    <account_code>1</account_code>
+  </account>
+  <transaction>
+    <id>a5143c1d3a6f4a8287d0e2cc1d4c0427</id>
+    <invoice_id>8fjk3sd7j90s0789k</invoice_id>
+    <invoice_number type="integer">2059</invoice_number>
+    <action>purchase</action>
+    <date type="datetime">2009-11-22T13:10:38Z</date>
+    <amount_in_cents type="integer">1000</amount_in_cents>
+    <status>Declined</status>
+    <message>This transaction has been declined</message>
+    <reference></reference>
+    <source>subscription</source>
+    <cvv_result code=""></cvv_result>
+    <avs_result code=""></avs_result>
+    <test type="boolean">true</test>
+    <voidable type="boolean">false</voidable>
+    <refundable type="boolean">false</refundable>
+  </transaction>
+</failed_payment_notification>`
+
+func TestParseNotification(t *testing.T) {
+	r := NewClient("")
+	n, err := r.ParseNotification([]byte(newSubscriptionNotification))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	sub, ok := n.(*NewSubscriptionNotification)
+	if !ok || sub.Type() != "new_subscription_notification" {
+		t.Fatalf("Unexpected notification %T", n)
+	}
+	if sub.Account.Email != "verena@example.com" || sub.Subscription.Plan.PlanCode != "bronze" || sub.Subscription.Plan.Name != "Bronze Plan" || sub.Subscription.Quantity != "2" || sub.Subscription.r != r {
+		t.Fatalf("Unexpected subscription %+v", sub.SubscriptionNotification)
+	}
+
+	n, err = r.ParseNotification([]byte(failedPaymentNotification))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	failed := n.(*FailedPaymentNotification)
+	if failed.Transaction.UUID != "a5143c1d3a6f4a8287d0e2cc1d4c0427" || failed.Transaction.InvoiceNumber != "2059" || failed.Transaction.AmountInCents != 1000 || !failed.Transaction.Test {
+		t.Fatalf("Unexpected transaction %+v", failed.Transaction)
+	}
+
+	n, err = r.ParseNotification([]byte(`<new_dunning_event_notification><account/></new_dunning_event_notification>`))
+	if unknown, ok := n.(*UnknownNotification); err != nil || !ok || unknown.Type() != "new_dunning_event_notification" {
+		t.Fatalf("Expected an unknown notification, got %T %v", n, err)
+	}
+}
+
+func TestNotificationHandler(t *testing.T) {
+	r := NewClient("")
+	h, err := r.NewNotificationHandler(WithNotificationAuth("recurly", "secret"), WithNotificationIPs("127.0.0.1", "10.0.0.0/8"), WithForwardedFor())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var got []string
+	h.OnFailedPayment(func(ctx context.Context, n *FailedPaymentNotification) error {
+		got = append(got, n.Transaction.Message)
+		if n.Account.AccountCode == "fail" {
+			return errors.New("provisioning is down")
+		}
+		return nil
+	})
+	h.OnNotification(func(ctx context.Context, n Notification) error {
+		got = append(got, n.Type())
+		return nil
+	})
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	post := func(body, user, forwarded string) int {
+		req, _ := http.NewRequest("POST", ts.URL, strings.NewReader(body))
+		if user != "" {
+			req.SetBasicAuth(user, "secret")
+		}
+		if forwarded != "" {
+			req.Header.Set("X-Forwarded-For", forwarded)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post(failedPaymentNotification, "recurly", "1.2.3.4, 10.1.2.3"); code != 200 {
+		t.Fatalf("Expected the notification to be accepted, got %v", code)
+	}
+	if len(got) != 2 || got[0] != "This transaction has been declined" || got[1] != "failed_payment_notification" {
+		t.Fatalf("Unexpected callbacks %v", got)
+	}
+	if code := post(failedPaymentNotification, "someone", "10.1.2.3"); code != 401 {
+		t.Fatalf("Expected wrong credentials to be refused, got %v", code)
+	}
+	if code := post(failedPaymentNotification, "recurly", "10.1.2.3, 8.8.8.8"); code != 403 {
+		t.Fatalf("Expected an address outside the allowlist to be refused, got %v", code)
+	}
+	if code := post("not xml", "recurly", ""); code != 400 {
+		t.Fatalf("Expected a bad body to be refused, got %v", code)
+	}
+	if code := post(strings.Replace(failedPaymentNotification, "<account_code>1<", "<account_code>fail<", 1), "recurly", ""); code != 500 {
+		t.Fatalf("Expected a failed callback to be retried, got %v", code)
+	}
+	if _, err := r.NewNotificationHandler(WithNotificationIPs("not an ip")); err == nil {
+		t.Fatal("Expected an invalid address to be rejected")
+	}
+	if _, err := r.NewNotificationHandler(); !errors.Is(err, ErrNotificationNoAuth) {
+		t.Fatalf("Expected a handler without auth to be refused, got %v", err)
+	}
+	if _, err := r.NewNotificationHandler(WithNotificationAuth("", "")); err == nil {
+		t.Fatal("Expected an empty username to be rejected")
+	}
+	if _, err := r.NewNotificationHandler(WithInsecureNoAuth()); err != nil {
+		t.Fatal(err.Error())
+	}
+}
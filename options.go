@@ -0,0 +1,73 @@
+package gorecurly
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+//An Option configures a Recurly client created with NewClient
+type Option func(*Recurly)
+
+//Create a new Recurly client with your apikey, configured by opts.
+//Without options the client talks to URL using its own http.Client.
+func NewClient(apikey string, opts ...Option) *Recurly {
+	r := new(Recurly)
+	r.apiKey = apikey
+	r.url = URL
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.client == nil {
+		r.client = &http.Client{}
+	}
+	if r.redactor == nil {
+		r.redactor = newRedactor(DefaultRedactedFields)
+	}
+	if r.timeout > 0 {
+		//copy the client so a caller supplied client is never modified
+		client := *r.client
+		client.Timeout = r.timeout
+		r.client = &client
+	}
+	return r
+}
+
+//Set the Recurly.js private key
+func WithJSKey(jskey string) Option {
+	return func(r *Recurly) {
+		r.JSKey = jskey
+	}
+}
+
+//Use client for every request instead of a default http.Client.
+//This is where proxies, TLS config and connection pooling are set.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Recurly) {
+		r.client = client
+	}
+}
+
+//Send requests to baseurl instead of URL, e.g. a mock server in tests
+func WithBaseURL(baseurl string) Option {
+	return func(r *Recurly) {
+		if !strings.HasSuffix(baseurl, "/") {
+			baseurl += "/"
+		}
+		r.url = baseurl
+	}
+}
+
+//Send requests to the api of a Recurly subdomain, https://subdomain.recurly.com/v2/
+func WithSubdomain(subdomain string) Option {
+	return func(r *Recurly) {
+		r.url = "https://" + subdomain + ".recurly.com/v2/"
+	}
+}
+
+//Set the overall time limit for a single request, including reading the response body
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Recurly) {
+		r.timeout = timeout
+	}
+}
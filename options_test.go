@@ -0,0 +1,61 @@
+package gorecurly
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewClientOptions(t *testing.T) {
+	client := &http.Client{}
+	r := NewClient("key", WithHTTPClient(client), WithSubdomain("acme"), WithTimeout(5*time.Second), WithJSKey("js"))
+	if r.url != "https://acme.recurly.com/v2/" {
+		t.Fatalf("Unexpected url %s", r.url)
+	}
+	if r.client.Timeout != 5*time.Second {
+		t.Fatal("Timeout was not applied to the client")
+	}
+	if client.Timeout != 0 {
+		t.Fatal("The caller supplied client should not be modified")
+	}
+	if r.JSKey != "js" {
+		t.Fatal("JSKey was not set")
+	}
+	if r = NewClient("key", WithBaseURL("http://localhost:8080")); r.url != "http://localhost:8080/" {
+		t.Fatalf("Unexpected url %s", r.url)
+	}
+}
+
+func TestConnectionReuse(t *testing.T) {
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<ok/>"))
+	}))
+	ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	for i := 0; i < 3; i++ {
+		tran := r.NewTransaction()
+		tran.UUID = "abc"
+		if err := tran.RefundAll(); err != nil {
+			t.Fatal(err.Error())
+		}
+		acc := r.NewAccount()
+		acc.AccountCode = "abc"
+		if err := acc.Update(); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Fatalf("Expected the requests to share one connection, got %v", n)
+	}
+}
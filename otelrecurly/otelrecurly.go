@@ -0,0 +1,87 @@
+//OpenTelemetry tracing for the Recurly client
+//
+//	r := gorecurly.NewClient(apikey, gorecurly.WithTracer(otelrecurly.NewTracer()))
+//
+//Every api call becomes a client span named after its operation and
+//resource, e.g. "recurly create subscriptions", a child of the span in the
+//context passed to the Ctx variant of the call.
+package otelrecurly
+
+import (
+	"context"
+
+	"github.com/mbeale/gorecurly"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/mbeale/gorecurly/otelrecurly"
+
+//An Option configures the Tracer
+type Option func(*config)
+
+type config struct {
+	provider trace.TracerProvider
+}
+
+//Use provider instead of the global TracerProvider
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+//Create a gorecurly.Tracer backed by OpenTelemetry
+func NewTracer(opts ...Option) gorecurly.Tracer {
+	c := config{provider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return tracer{c.provider.Tracer(instrumentationName)}
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+//Start a client span for an api call
+func (t tracer) Start(ctx context.Context, info gorecurly.RequestInfo) (context.Context, gorecurly.Span) {
+	ctx, s := t.tracer.Start(ctx, "recurly "+info.Operation+" "+info.Resource,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("recurly.resource", info.Resource),
+			attribute.String("recurly.operation", info.Operation),
+			attribute.String("recurly.endpoint", info.Endpoint),
+			attribute.String("http.request.method", info.Method),
+		))
+	return ctx, &span{span: s}
+}
+
+type span struct {
+	span trace.Span
+	//Status code of the latest attempt
+	status int
+}
+
+//Record the status and Recurly request id of an attempt.  The span status is
+//set when it ends, an error status can not be taken back once a retry succeeds.
+func (s *span) SetResponse(status int, requestID string) {
+	s.status = status
+	s.span.SetAttributes(
+		attribute.Int("http.response.status_code", status),
+		attribute.String("recurly.request_id", requestID),
+	)
+}
+
+//End the span, recording err or the error status of the last attempt
+func (s *span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	} else if s.status >= 400 {
+		s.span.SetStatus(codes.Error, "")
+	}
+	s.span.End()
+}
@@ -0,0 +1,119 @@
+package otelrecurly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mbeale/gorecurly"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+}
+
+func attributes(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSpan(t *testing.T) {
+	recorder, provider := newRecorder()
+	tr := NewTracer(WithTracerProvider(provider))
+	info := gorecurly.RequestInfo{Method: "POST", Endpoint: "subscriptions", Resource: "subscriptions", Operation: "create"}
+
+	_, s := tr.Start(context.Background(), info)
+	s.SetResponse(201, "req-1")
+	s.End(nil)
+	_, s = tr.Start(context.Background(), info)
+	s.SetResponse(503, "req-2")
+	s.End(nil)
+	_, s = tr.Start(context.Background(), info)
+	s.SetResponse(503, "req-3")
+	s.SetResponse(201, "req-4")
+	s.End(nil)
+	_, s = tr.Start(context.Background(), info)
+	s.SetResponse(422, "req-5")
+	s.End(errors.New("declined"))
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(spans))
+	}
+	first := spans[0]
+	if first.Name() != "recurly create subscriptions" || first.SpanKind() != trace.SpanKindClient {
+		t.Fatalf("Unexpected span %q %v", first.Name(), first.SpanKind())
+	}
+	attrs := attributes(first)
+	expected := map[attribute.Key]attribute.Value{
+		"recurly.resource":          attribute.StringValue("subscriptions"),
+		"recurly.operation":         attribute.StringValue("create"),
+		"recurly.endpoint":          attribute.StringValue("subscriptions"),
+		"http.request.method":       attribute.StringValue("POST"),
+		"http.response.status_code": attribute.IntValue(201),
+		"recurly.request_id":        attribute.StringValue("req-1"),
+	}
+	for k, v := range expected {
+		if attrs[k] != v {
+			t.Fatalf("Expected %s to be %v, got %v", k, v.Emit(), attrs[k].Emit())
+		}
+	}
+
+	statuses := []codes.Code{codes.Unset, codes.Error, codes.Unset, codes.Error}
+	for i, s := range spans {
+		if s.Status().Code != statuses[i] {
+			t.Fatalf("Expected span %d to have status %v, got %v", i, statuses[i], s.Status().Code)
+		}
+	}
+	//a retry that succeeds records the last attempt
+	if attributes(spans[2])["recurly.request_id"] != attribute.StringValue("req-4") {
+		t.Fatal("Expected the request id of the last attempt")
+	}
+	last := spans[3]
+	if last.Status().Description != "declined" || len(last.Events()) != 1 || last.Events()[0].Name != "exception" {
+		t.Fatalf("Expected the error to be recorded, got %+v %+v", last.Status(), last.Events())
+	}
+}
+
+func TestClientSpans(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(404)
+	}))
+	defer ts.Close()
+
+	recorder, provider := newRecorder()
+	r := gorecurly.NewClient("", gorecurly.WithBaseURL(ts.URL), gorecurly.WithTracer(NewTracer(WithTracerProvider(provider))))
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	if _, err := r.GetAccountCtx(ctx, "missing"); !errors.Is(err, gorecurly.ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected the call and parent spans, got %d", len(spans))
+	}
+	call := spans[0]
+	if call.Name() != "recurly get accounts" || call.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("Unexpected span %q with parent %v", call.Name(), call.Parent().SpanID())
+	}
+	attrs := attributes(call)
+	if attrs["http.response.status_code"] != attribute.IntValue(404) || attrs["recurly.request_id"] != attribute.StringValue("req-1") {
+		t.Fatalf("Unexpected attributes %v", attrs)
+	}
+	if call.Status().Code != codes.Error {
+		t.Fatalf("Expected an error status, got %v", call.Status().Code)
+	}
+}
@@ -0,0 +1,116 @@
+package gorecurly
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"iter"
+	"net/url"
+)
+
+//Resources that are bound to a client when they are decoded from a list
+type attacher interface {
+	attach(r *Recurly)
+}
+
+//A generic pager over a list endpoint, following the cursor from page to page
+type listPager[T any] struct {
+	r        *Recurly
+	endpoint string
+	paging   Paging
+}
+
+//Request one page and yield its items as they are decoded from the response,
+//without reading the whole body first. Returns false when yield asked to stop.
+func (p *listPager[T]) page(ctx context.Context, params url.Values, yield func(T, error) bool) (bool, error) {
+	resp, err := p.r.createRequest(withOperation(ctx, "list"), p.endpoint, "GET", params, nil)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode >= 400 {
+		return false, p.r.createRecurlyError(resp)
+	}
+	defer resp.Body.Close()
+	p.paging.SetData(nil, resp.Header.Get("X-Records"), resp.Header.Get("Link"), params)
+	var body io.Reader = resp.Body
+	if p.r.debug {
+		//the debug output needs the whole page
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return false, err
+		}
+		p.r.logResponseBody(ctx, resp, b)
+		body = bytes.NewReader(b)
+	}
+	d := xml.NewDecoder(body)
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 1 {
+				var item T
+				if err := d.DecodeElement(&item, &t); err != nil {
+					return false, err
+				}
+				if a, ok := any(&item).(attacher); ok {
+					a.attach(p.r)
+				}
+				if !yield(item, nil) {
+					return false, nil
+				}
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+//Iterate over every item of a list endpoint, fetching pages as needed.
+//Items are decoded one at a time from the response stream.
+//An error ends the iteration after it is yielded.
+func listAll[T any](ctx context.Context, r *Recurly, endpoint string, params []url.Values) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		p := &listPager[T]{r: r, endpoint: endpoint}
+		sendvars := p.paging.initParams(params)
+		for {
+			more, err := p.page(ctx, sendvars, yield)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !more || p.paging.next == "" {
+				return
+			}
+			sendvars = p.paging.NextParams()
+		}
+	}
+}
+
+//Replace list with the page returned by fetch if there is a cursor to go to.
+//A failed fetch keeps the current page and is recorded for Err.
+func movePage[S any, P interface {
+	*S
+	setErr(error)
+}](list P, cursor string, fetch func() (S, error)) bool {
+	if cursor == "" {
+		return false
+	}
+	page, err := fetch()
+	if err != nil {
+		list.setErr(err)
+		return false
+	}
+	*list = page
+	return true
+}
@@ -0,0 +1,140 @@
+package gorecurly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPager(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Records", "3")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			if r.URL.Query().Get("state") != "active" {
+				w.WriteHeader(400)
+				return
+			}
+			w.Header().Set("Link", `<https://api.recurly.com/v2/accounts?cursor=2>; rel="next"`)
+			fmt.Fprint(w, `<accounts type="array"><account><account_code>a1</account_code></account><account><account_code>a2</account_code></account></accounts>`)
+		case "2":
+			w.Header().Set("Link", `<https://api.recurly.com/v2/accounts?cursor=3>; rel="next"`)
+			fmt.Fprint(w, `<accounts type="array"><account><account_code>a3</account_code></account></accounts>`)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL), WithRetryPolicy(RetryPolicy{}))
+	params := url.Values{"state": {"active"}}
+	var codes []string
+	var last error
+	for acc, err := range r.Accounts(context.Background(), params) {
+		if err != nil {
+			last = err
+			break
+		}
+		if acc.r != r {
+			t.Fatal("Expected the account to be bound to the client")
+		}
+		codes = append(codes, acc.AccountCode)
+	}
+	if fmt.Sprint(codes) != "[a1 a2 a3]" {
+		t.Fatalf("Unexpected accounts %v", codes)
+	}
+	if !errors.Is(last, ErrNotFound) {
+		t.Fatalf("Expected the error of the last page, got %v", last)
+	}
+	if params.Get("cursor") != "" {
+		t.Fatal("The caller's params should not be changed")
+	}
+
+	accounts, err := r.GetAccounts(params)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !accounts.Next() || accounts.Account[0].AccountCode != "a3" {
+		t.Fatal("Expected the second page")
+	}
+	if accounts.Next() || !errors.Is(accounts.Err(), ErrNotFound) || accounts.Account[0].AccountCode != "a3" {
+		t.Fatalf("Expected the failed page to be kept and the error recorded, got %v", accounts.Err())
+	}
+}
+
+func TestCount(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subscriptions" || r.URL.Query().Get("state") != "past_due" {
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("X-Records", "42")
+		if r.Method == "HEAD" {
+			return
+		}
+		fmt.Fprint(w, `<subscriptions type="array"></subscriptions>`)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	var ops []string
+	r.middleware = append(r.middleware, BeforeRequest(func(req *http.Request) error {
+		info, _ := RequestInfoFromContext(req.Context())
+		ops = append(ops, req.Method+" "+info.Operation)
+		return nil
+	}))
+	opts := SubscriptionListOptions{State: SubscriptionStatePastDue}
+	if n, err := r.CountSubscriptions(opts); err != nil || n != 42 {
+		t.Fatalf("Expected 42 subscriptions, got %v %v", n, err)
+	}
+	params, _ := opts.Values()
+	subs, err := r.GetSubscriptions(params)
+	if err != nil || subs.Count() != 42 {
+		t.Fatalf("Expected the list count, got %v %v", subs.Count(), err)
+	}
+	if _, err := r.CountAccounts(AccountListOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	if _, err := r.CountInvoices(InvoiceListOptions{State: "unknown"}); !errors.Is(err, ErrInvalidListOption) {
+		t.Fatalf("Expected the options to be rejected, got %v", err)
+	}
+	if fmt.Sprint(ops) != "[HEAD count GET list HEAD count]" {
+		t.Fatalf("Unexpected requests %v", ops)
+	}
+}
+
+func TestPagerStreams(t *testing.T) {
+	first := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Records", "2")
+		fmt.Fprint(w, `<invoices type="array"><invoice><invoice_number>1001</invoice_number></invoice>`)
+		w.(http.Flusher).Flush()
+		//the rest of the page is only sent once the first invoice was handed out
+		select {
+		case <-first:
+		case <-time.After(5 * time.Second):
+		}
+		fmt.Fprint(w, `<invoice><invoice_number>1002</invoice_number></invoice></invoices>`)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	var numbers []string
+	for inv, err := range r.Invoices(context.Background()) {
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if len(numbers) == 0 {
+			close(first)
+		}
+		numbers = append(numbers, inv.InvoiceNumber)
+	}
+	if fmt.Sprint(numbers) != "[1001 1002]" {
+		t.Fatalf("Unexpected invoices %v", numbers)
+	}
+}
@@ -0,0 +1,176 @@
+package gorecurly
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"sync"
+	"time"
+)
+
+//An item or error handed from a background fetch to the caller
+type result[T any] struct {
+	item T
+	err  error
+}
+
+//Range over seq and send every result to ch until seq is done, fails or stop is closed
+func send[T any](seq iter.Seq2[T, error], ch chan<- result[T], stop <-chan struct{}) {
+	defer close(ch)
+	for item, err := range seq {
+		select {
+		case ch <- result[T]{item, err}:
+		case <-stop:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+//Iterate over a list while the following page is fetched in the background.
+//buffer is the number of items fetched ahead of the caller, usually the page size.
+//Breaking out of the loop stops the fetching once the page in flight is done.
+//
+//	for sub, err := range gorecurly.Prefetch(r.Subscriptions(ctx, params), 200) {
+func Prefetch[T any](seq iter.Seq2[T, error], buffer int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ch := make(chan result[T], buffer)
+		stop := make(chan struct{})
+		defer close(stop)
+		go send(seq, ch, stop)
+		for res := range ch {
+			if !yield(res.item, res.err) {
+				return
+			}
+		}
+	}
+}
+
+//A list iterator such as r.Subscriptions or r.Accounts
+type ListFunc[T any] func(ctx context.Context, params ...url.Values) iter.Seq2[T, error]
+
+//Options for ParallelWalk
+type WalkOptions struct {
+	//Filters sent with every shard, begin_time and end_time are replaced
+	Params url.Values
+	//The range to walk, split into Shards windows of equal length
+	BeginTime time.Time
+	EndTime   time.Time
+	Shards    int
+	//Number of shards fetched at once, defaults to Shards
+	Concurrency int
+	//Number of items buffered for each shard, defaults to 200
+	Buffer int
+}
+
+//Walk a large time range by splitting it into shards that are fetched concurrently.
+//Items are yielded in the order of the windows, ascending unless Params has order=desc,
+//so the result is the same as walking the whole range with a single list.
+//Breaking out of the loop cancels the shards that are still being fetched.
+func ParallelWalk[T any](ctx context.Context, list ListFunc[T], opts WalkOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		windows, err := opts.windows()
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		concurrency := opts.Concurrency
+		if concurrency <= 0 || concurrency > len(windows) {
+			concurrency = len(windows)
+		}
+		buffer := opts.Buffer
+		if buffer <= 0 {
+			buffer = 200
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		defer func() {
+			close(stop)
+			cancel()
+			wg.Wait()
+		}()
+
+		shards := make([]chan result[T], len(windows))
+		for i := range shards {
+			shards[i] = make(chan result[T], buffer)
+		}
+		//shards are started in order so the one being read always holds a slot
+		slots := make(chan struct{}, concurrency)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, params := range windows {
+				select {
+				case slots <- struct{}{}:
+				case <-stop:
+					return
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-slots }()
+					send(list(ctx, params), shards[i], stop)
+				}()
+			}
+		}()
+
+		for _, ch := range shards {
+			for res := range ch {
+				if !yield(res.item, res.err) || res.err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+//Split the range into the params for every shard, in the order they are yielded.
+//Windows are aligned to whole seconds and do not overlap.
+func (o WalkOptions) windows() ([]url.Values, error) {
+	shards := o.Shards
+	if shards <= 0 {
+		shards = 1
+	}
+	begin := o.BeginTime.UTC().Truncate(time.Second)
+	end := o.EndTime.UTC().Truncate(time.Second)
+	if o.BeginTime.IsZero() || o.EndTime.IsZero() || !end.After(begin) {
+		return nil, fmt.Errorf("%w (end_time must be after begin_time)", ErrInvalidListOption)
+	}
+	span := end.Sub(begin)
+	if seconds := int(span / time.Second); shards > seconds {
+		shards = seconds
+	}
+	desc := o.Params.Get("order") == string(OrderDesc)
+	windows := make([]url.Values, shards)
+	for i := 0; i < shards; i++ {
+		from := begin.Add(span * time.Duration(i) / time.Duration(shards)).Truncate(time.Second)
+		to := end
+		if i < shards-1 {
+			to = begin.Add(span*time.Duration(i+1)/time.Duration(shards)).Truncate(time.Second).Add(-time.Second)
+		}
+		v := url.Values{}
+		for k, vals := range o.Params {
+			v[k] = append([]string(nil), vals...)
+		}
+		if v.Get("sort") == "" {
+			v.Set("sort", string(SortCreatedAt))
+		}
+		if !desc {
+			v.Set("order", string(OrderAsc))
+		}
+		v.Set("begin_time", from.Format(time.RFC3339))
+		v.Set("end_time", to.Format(time.RFC3339))
+		if desc {
+			windows[shards-1-i] = v
+		} else {
+			windows[i] = v
+		}
+	}
+	return windows, nil
+}
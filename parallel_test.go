@@ -0,0 +1,95 @@
+package gorecurly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelWalk(t *testing.T) {
+	var inflight, most int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for m := atomic.LoadInt32(&most); n > m && !atomic.CompareAndSwapInt32(&most, m, n); m = atomic.LoadInt32(&most) {
+		}
+		time.Sleep(20 * time.Millisecond)
+		q := r.URL.Query()
+		if q.Get("state") != "active" || q.Get("sort") != "created_at" || q.Get("order") != "asc" {
+			w.WriteHeader(400)
+			return
+		}
+		w.Header().Set("X-Records", "2")
+		fmt.Fprintf(w, `<accounts type="array"><account><account_code>%s</account_code></account><account><account_code>%s</account_code></account></accounts>`, q.Get("begin_time"), q.Get("end_time"))
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	begin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts := WalkOptions{
+		Params:      url.Values{"state": {"active"}},
+		BeginTime:   begin,
+		EndTime:     begin.Add(4 * time.Hour),
+		Shards:      4,
+		Concurrency: 2,
+	}
+	var codes []string
+	for acc, err := range ParallelWalk(context.Background(), r.Accounts, opts) {
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		codes = append(codes, acc.AccountCode)
+	}
+	expected := "[2024-01-01T00:00:00Z 2024-01-01T00:59:59Z 2024-01-01T01:00:00Z 2024-01-01T01:59:59Z 2024-01-01T02:00:00Z 2024-01-01T02:59:59Z 2024-01-01T03:00:00Z 2024-01-01T04:00:00Z]"
+	if fmt.Sprint(codes) != expected {
+		t.Fatalf("Unexpected order %v", codes)
+	}
+	if atomic.LoadInt32(&most) != 2 {
+		t.Fatalf("Expected 2 shards at once, got %v", most)
+	}
+
+	var seen int
+	for range ParallelWalk(context.Background(), r.Accounts, opts) {
+		if seen++; seen == 3 {
+			break
+		}
+	}
+	if seen != 3 {
+		t.Fatalf("Expected the walk to stop, got %v", seen)
+	}
+	for _, e := range ParallelWalk(context.Background(), r.Accounts, WalkOptions{BeginTime: begin, EndTime: begin}) {
+		if e == nil {
+			t.Fatal("Expected an empty range to be rejected")
+		}
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Records", "3")
+		if r.URL.Query().Get("cursor") == "" {
+			w.Header().Set("Link", `<https://api.recurly.com/v2/accounts?cursor=2>; rel="next"`)
+			fmt.Fprint(w, `<accounts type="array"><account><account_code>a1</account_code></account><account><account_code>a2</account_code></account></accounts>`)
+			return
+		}
+		fmt.Fprint(w, `<accounts type="array"><account><account_code>a3</account_code></account></accounts>`)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	var codes []string
+	for acc, err := range Prefetch(r.Accounts(context.Background()), 2) {
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		codes = append(codes, acc.AccountCode)
+	}
+	if fmt.Sprint(codes) != "[a1 a2 a3]" {
+		t.Fatalf("Unexpected accounts %v", codes)
+	}
+}
@@ -1,7 +1,10 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
+	"iter"
+	"net/url"
 )
 
 //Plan add on list
@@ -15,30 +18,42 @@ type PlanAddOnList struct {
 
 //Get next set of Coupons
 func (p *PlanAddOnList) Next() bool {
-	if p.next != "" {
-		*p, _ = p.r.GetPlanAddOns(p.PlanCode,p.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return p.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (p *PlanAddOnList) NextCtx(ctx context.Context) bool {
+	return movePage(p, p.next, func() (PlanAddOnList, error) {
+		return p.r.GetPlanAddOnsCtx(ctx, p.PlanCode,p.NextParams())
+	})
 }
 
 //Get previous set of accounts
 func (p *PlanAddOnList) Prev() bool {
-	if p.prev != "" {
-		*p, _ = p.r.GetPlanAddOns(p.PlanCode,p.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return p.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (p *PlanAddOnList) PrevCtx(ctx context.Context) bool {
+	return movePage(p, p.prev, func() (PlanAddOnList, error) {
+		return p.r.GetPlanAddOnsCtx(ctx, p.PlanCode,p.PrevParams())
+	})
 }
 
 //Go to start set of accounts
 func (p *PlanAddOnList) Start() bool {
-	if p.prev != "" {
-		*p, _ = p.r.GetPlanAddOns(p.PlanCode,p.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return p.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (p *PlanAddOnList) StartCtx(ctx context.Context) bool {
+	return movePage(p, p.prev, func() (PlanAddOnList, error) {
+		return p.r.GetPlanAddOnsCtx(ctx, p.PlanCode,p.StartParams())
+	})
+}
+
+//Iterate over all add ons for a plan_code, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) PlanAddOns(ctx context.Context, plan_code string, params ...url.Values) iter.Seq2[PlanAddOn, error] {
+	return listAll[PlanAddOn](ctx, r, PLANS+"/"+plan_code+"/"+PLANADDONS, params)
 }
@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"time"
@@ -33,14 +34,24 @@ type tempPlanAddOn struct {
 
 //Create plan add on given a plan code
 func (p *PlanAddOn) Create(plan_code string) error {
+	return p.CreateCtx(context.Background(), plan_code)
+}
+
+//Same as Create, bound to a context
+func (p *PlanAddOn) CreateCtx(ctx context.Context, plan_code string) error {
 	if p.CreatedAt != nil {
-		return RecurlyError{statusCode: 400, Description: "Add on Code Already in Use"}
+		return &APIError{StatusCode: 400, Description: "Add on Code Already in Use"}
 	}
-	return p.r.doCreate(&p, PLANS+"/"+plan_code+"/add_ons")
+	return p.r.doCreate(ctx, &p, PLANS+"/"+plan_code+"/add_ons")
 }
 
 //Update a plan add on
 func (p *PlanAddOn) Update() error {
+	return p.UpdateCtx(context.Background())
+}
+
+//Same as Update, bound to a context
+func (p *PlanAddOn) UpdateCtx(ctx context.Context) error {
 	newaddon := new(tempPlanAddOn)
 	newaddon.Name = p.Name
 	newaddon.DisplayQuantityOnHostedPage = p.DisplayQuantityOnHostedPage
@@ -60,14 +71,22 @@ func (p *PlanAddOn) Update() error {
 	}
 
 	if p.Plan != nil {
-		return p.r.doUpdate(newaddon, PLANS+"/"+p.Plan.GetCode()+"/add_ons/"+p.AddOnCode)
+		return p.r.doUpdate(ctx, newaddon, PLANS+"/"+p.Plan.GetCode()+"/add_ons/"+p.AddOnCode)
 	}
 	return errors.New("Plan Does not exist")
 }
 
 //Delete plan add on
 func (p *PlanAddOn) Delete() error {
-	return p.r.doDelete(PLANS + "/" + p.Plan.GetCode() + "/add_ons/" + p.AddOnCode)
+	return p.DeleteCtx(context.Background())
 }
 
+//Same as Delete, bound to a context
+func (p *PlanAddOn) DeleteCtx(ctx context.Context) error {
+	return p.r.doDelete(ctx, PLANS + "/" + p.Plan.GetCode() + "/add_ons/" + p.AddOnCode)
+}
 
+//Bind the add on to a client after it is decoded from a list
+func (p *PlanAddOn) attach(r *Recurly) {
+	p.r = r
+}
@@ -1,7 +1,10 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
+	"iter"
+	"net/url"
 )
 
 //Listing of plans
@@ -14,30 +17,42 @@ type PlanList struct {
 
 //Get next set of Coupons
 func (p *PlanList) Next() bool {
-	if p.next != "" {
-		*p, _ = p.r.GetPlans(p.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return p.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (p *PlanList) NextCtx(ctx context.Context) bool {
+	return movePage(p, p.next, func() (PlanList, error) {
+		return p.r.GetPlansCtx(ctx, p.NextParams())
+	})
 }
 
 //Get previous set of accounts
 func (p *PlanList) Prev() bool {
-	if p.prev != "" {
-		*p, _ = p.r.GetPlans(p.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return p.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (p *PlanList) PrevCtx(ctx context.Context) bool {
+	return movePage(p, p.prev, func() (PlanList, error) {
+		return p.r.GetPlansCtx(ctx, p.PrevParams())
+	})
 }
 
 //Go to start set of accounts
 func (p *PlanList) Start() bool {
-	if p.prev != "" {
-		*p, _ = p.r.GetPlans(p.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return p.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (p *PlanList) StartCtx(ctx context.Context) bool {
+	return movePage(p, p.prev, func() (PlanList, error) {
+		return p.r.GetPlansCtx(ctx, p.StartParams())
+	})
+}
+
+//Iterate over all plans, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) Plans(ctx context.Context, params ...url.Values) iter.Seq2[Plan, error] {
+	return listAll[Plan](ctx, r, PLANS, params)
 }
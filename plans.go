@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"time"
 )
@@ -44,14 +45,24 @@ type Plan struct {
 
 //Create a plan
 func (p *Plan) Create() error {
+	return p.CreateCtx(context.Background())
+}
+
+//Same as Create, bound to a context
+func (p *Plan) CreateCtx(ctx context.Context) error {
 	if p.CreatedAt != nil {
-		return RecurlyError{statusCode: 400, Description: "Plan Code Already in Use"}
+		return &APIError{StatusCode: 400, Description: "Plan Code Already in Use"}
 	}
-	return p.r.doCreate(&p, p.endpoint)
+	return p.r.doCreate(ctx, &p, p.endpoint)
 }
 
 //Update a plan
 func (p *Plan) Update() error {
+	return p.UpdateCtx(context.Background())
+}
+
+//Same as Update, bound to a context
+func (p *Plan) UpdateCtx(ctx context.Context) error {
 	newplan := new(tempPlan)
 	newplan.Name = p.Name
 	newplan.PlanCode = p.PlanCode
@@ -79,18 +90,25 @@ func (p *Plan) Update() error {
 		newplan.UnitAmountInCents = nil
 	}
 
-	return p.r.doUpdate(newplan, p.endpoint+"/"+p.PlanCode)
+	return p.r.doUpdate(ctx, newplan, p.endpoint+"/"+p.PlanCode)
 }
 
 //Delete a plan
 func (p *Plan) Delete() error {
-	return p.r.doDelete(p.endpoint + "/" + p.PlanCode)
+	return p.DeleteCtx(context.Background())
 }
 
-//Plan Stub struct
+//Same as Delete, bound to a context
+func (p *Plan) DeleteCtx(ctx context.Context) error {
+	return p.r.doDelete(ctx, p.endpoint + "/" + p.PlanCode)
+}
+
+//Plan Stub struct, push notifications carry the plan code and name instead of a link
 type PlanStub struct {
 	XMLName xml.Name `xml:"plan"`
 	stub
+	PlanCode string `xml:"plan_code,omitempty"`
+	Name     string `xml:"name,omitempty"`
 }
 
 //A struct to be embedded for plan_code
@@ -99,3 +117,8 @@ type PlanCode struct {
 	PlanCode []string `xml:"plan_code"`
 }
 
+//Bind the plan to a client after it is decoded from a list
+func (p *Plan) attach(r *Recurly) {
+	p.r = r
+	p.endpoint = PLANS
+}
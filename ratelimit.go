@@ -0,0 +1,152 @@
+package gorecurly
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//The rate limit state Recurly reported on the latest response
+type RateLimit struct {
+	//Requests allowed in the current window
+	Limit int
+	//Requests left in the current window
+	Remaining int
+	//When the current window ends
+	Reset time.Time
+}
+
+//Return the rate limit state of the latest response, the zero value if
+//Recurly has not reported one yet
+func (r *Recurly) RateLimit() RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ratelimit
+}
+
+//Record the rate limit headers of a response
+func (r *Recurly) recordRateLimit(h http.Header) {
+	rl, ok := parseRateLimit(h)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	r.ratelimit = rl
+	r.mu.Unlock()
+	if o, ok := r.limiter.(rateLimitObserver); ok {
+		o.Observe(rl)
+	}
+}
+
+func parseRateLimit(h http.Header) (rl RateLimit, ok bool) {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return rl, false
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return rl, false
+	}
+	rl.Limit = limit
+	rl.Remaining = remaining
+	if epoch, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(epoch, 0)
+	}
+	return rl, true
+}
+
+//A Limiter is asked for permission before every request is sent.
+//Wait blocks until the request may go out or ctx is done.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+//Implemented by limiters that adjust to the rate limit Recurly reports
+type rateLimitObserver interface {
+	Observe(RateLimit)
+}
+
+//Throttle every request through limiter
+func WithLimiter(limiter Limiter) Option {
+	return func(r *Recurly) {
+		r.limiter = limiter
+	}
+}
+
+//A token bucket Limiter.  Besides its own rate it follows the remaining
+//requests Recurly reports, so clients sharing an api key slow down together
+//and wait for the window to reset instead of running into Error429.
+type TokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	perToken time.Duration
+	last     time.Time
+	blocked  time.Time
+}
+
+//Create a token bucket allowing n requests per interval, in bursts of up to n.
+//Without a positive n and interval it sets no rate of its own and only
+//follows the rate limit Recurly reports.
+func NewTokenBucket(n int, per time.Duration) *TokenBucket {
+	if n <= 0 || per <= 0 {
+		return &TokenBucket{last: time.Now()}
+	}
+	return &TokenBucket{
+		capacity: float64(n),
+		tokens:   float64(n),
+		perToken: per / time.Duration(n),
+		last:     time.Now(),
+	}
+}
+
+//Wait for a token
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		wait := b.take(time.Now())
+		if wait == 0 {
+			return nil
+		}
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+//Take a token at now, or return how long to wait for one
+func (b *TokenBucket) take(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if now.Before(b.blocked) {
+		return b.blocked.Sub(now)
+	}
+	if b.perToken <= 0 {
+		return 0
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) / float64(b.perToken)
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.perToken))
+}
+
+//Adjust the bucket to the rate limit Recurly reported
+func (b *TokenBucket) Observe(rl RateLimit) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if float64(rl.Remaining) < b.tokens {
+		b.tokens = float64(rl.Remaining)
+	}
+	if rl.Remaining <= 0 && rl.Reset.After(time.Now()) {
+		b.blocked = rl.Reset
+	}
+}
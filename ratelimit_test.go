@@ -0,0 +1,63 @@
+package gorecurly
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitRecorded(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "2000")
+		w.Header().Set("X-RateLimit-Remaining", "1999")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%v", reset))
+		fmt.Fprintf(w, "%s", accountGet)
+	}))
+	defer ts.Close()
+
+	bucket := NewTokenBucket(10, time.Second)
+	r := NewClient("", WithBaseURL(ts.URL), WithLimiter(bucket))
+	if _, e := r.GetAccount("test21"); e != nil {
+		t.Fatal(e.Error())
+	}
+	rl := r.RateLimit()
+	if rl.Limit != 2000 || rl.Remaining != 1999 || rl.Reset.Unix() != reset {
+		t.Fatalf("Unexpected rate limit %+v", rl)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := NewTokenBucket(2, time.Second)
+	now := b.last
+	if b.take(now) != 0 || b.take(now) != 0 {
+		t.Fatal("Expected a burst of 2 requests")
+	}
+	if wait := b.take(now); wait != 500*time.Millisecond {
+		t.Fatalf("Expected to wait 500ms, got %v", wait)
+	}
+	if b.take(now.Add(500*time.Millisecond)) != 0 {
+		t.Fatal("Expected a token after 500ms")
+	}
+	b.Observe(RateLimit{Limit: 2000, Remaining: 0, Reset: now.Add(time.Hour)})
+	if wait := b.take(now.Add(time.Minute)); wait <= 0 {
+		t.Fatal("Expected to wait for the rate limit window to reset")
+	}
+}
+
+func TestTokenBucketWithoutRate(t *testing.T) {
+	for _, b := range []*TokenBucket{NewTokenBucket(0, time.Second), NewTokenBucket(-1, time.Second), NewTokenBucket(10, 0)} {
+		now := b.last
+		for i := 0; i < 100; i++ {
+			if wait := b.take(now); wait != 0 {
+				t.Fatalf("Expected no wait, got %v", wait)
+			}
+		}
+		b.Observe(RateLimit{Limit: 2000, Remaining: 0, Reset: now.Add(time.Hour)})
+		if wait := b.take(now.Add(time.Minute)); wait <= 0 {
+			t.Fatal("Expected to wait for the rate limit window to reset")
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package gorecurly
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoJSKey            = errors.New("No Recurly.js private key, set it with WithJSKey.")
+	ErrInvalidSignature   = errors.New("The Recurly.js signature is not valid.")
+	ErrSignatureExpired   = errors.New("The Recurly.js signature has expired.")
+	ErrInvalidResultToken = errors.New("The Recurly.js result token is not valid.")
+)
+
+//Protected params of a Recurly.js form.  Values are strings, numbers, nested JSParams or slices of them,
+//they are encoded as nested params, e.g. account[account_code]=a1.
+type JSParams map[string]interface{}
+
+//Sign the protected params of a subscription form
+func (r *Recurly) SignSubscription(account_code, plan_code string) (string, error) {
+	return r.SignJS(JSParams{
+		"account":      JSParams{"account_code": account_code},
+		"subscription": JSParams{"plan_code": plan_code},
+	})
+}
+
+//Sign the protected params of a one time transaction form
+func (r *Recurly) SignTransaction(account_code string, amount_in_cents int, currency string) (string, error) {
+	return r.SignJS(JSParams{
+		"account":     JSParams{"account_code": account_code},
+		"transaction": JSParams{"amount_in_cents": amount_in_cents, "currency": currency},
+	})
+}
+
+//Sign the protected params of a billing info update form
+func (r *Recurly) SignBillingInfoUpdate(account_code string) (string, error) {
+	return r.SignJS(JSParams{"account": JSParams{"account_code": account_code}})
+}
+
+//Sign protected params with the JSKey for Recurly.js v2.  A timestamp and nonce are added
+//unless params already has them.  The signature is the HMAC-SHA1 of the encoded params, a pipe and the encoded params.
+func (r *Recurly) SignJS(params JSParams) (string, error) {
+	if r.JSKey == "" {
+		return "", ErrNoJSKey
+	}
+	data := JSParams{}
+	for k, v := range params {
+		data[k] = v
+	}
+	if _, ok := data["timestamp"]; !ok {
+		data["timestamp"] = time.Now().Unix()
+	}
+	if _, ok := data["nonce"]; !ok {
+		nonce := make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		data["nonce"] = hex.EncodeToString(nonce)
+	}
+	unsigned, err := encodeJSParams(data, "")
+	if err != nil {
+		return "", err
+	}
+	return r.jsDigest(unsigned) + "|" + unsigned, nil
+}
+
+//Check a signature made with the JSKey and return its params.
+//Signatures older than maxAge are rejected, a maxAge of 0 accepts any age.
+func (r *Recurly) VerifyJSSignature(signature string, maxAge time.Duration) (url.Values, error) {
+	if r.JSKey == "" {
+		return nil, ErrNoJSKey
+	}
+	parts := strings.SplitN(signature, "|", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[0]), []byte(r.jsDigest(parts[1]))) {
+		return nil, ErrInvalidSignature
+	}
+	params, err := url.ParseQuery(parts[1])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if maxAge > 0 {
+		ts, err := strconv.ParseInt(params.Get("timestamp"), 10, 64)
+		if err != nil {
+			return nil, ErrInvalidSignature
+		}
+		if time.Since(time.Unix(ts, 0)) > maxAge {
+			return nil, ErrSignatureExpired
+		}
+	}
+	return params, nil
+}
+
+//Hex HMAC-SHA1 of the unsigned params
+func (r *Recurly) jsDigest(unsigned string) string {
+	mac := hmac.New(sha1.New, []byte(r.JSKey))
+	mac.Write([]byte(unsigned))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//Encode v as nested params the way the Recurly client libraries do,
+//the entries of a map are sorted and the items of a slice keep their order
+func encodeJSParams(v interface{}, key string) (string, error) {
+	switch t := v.(type) {
+	case JSParams:
+		return encodeJSParams(map[string]interface{}(t), key)
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(t))
+		for k, item := range t {
+			if key != "" {
+				k = key + "[" + k + "]"
+			}
+			s, err := encodeJSParams(item, k)
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, s)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, "&"), nil
+	case []interface{}:
+		pairs := make([]string, 0, len(t))
+		for _, item := range t {
+			s, err := encodeJSParams(item, key+"[]")
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, s)
+		}
+		return strings.Join(pairs, "&"), nil
+	case []string:
+		items := make([]interface{}, len(t))
+		for k := range t {
+			items[k] = t[k]
+		}
+		return encodeJSParams(items, key)
+	case string, int, int64, bool:
+		return url.QueryEscape(key) + "=" + url.QueryEscape(fmt.Sprint(t)), nil
+	}
+	return "", fmt.Errorf("Recurly.js param %s has an unsupported type %T", key, v)
+}
+
+//The object created by a Recurly.js form, only the field of the form type is set
+type JSResult struct {
+	Subscription *Subscription
+	Transaction  *Transaction
+	BillingInfo  *BillingInfo
+}
+
+//Fetch the subscription, transaction or billing info created by a Recurly.js form from its result token
+func (r *Recurly) FetchResult(token string) (JSResult, error) {
+	return r.FetchResultCtx(context.Background(), token)
+}
+
+//Same as FetchResult, bound to a context
+func (r *Recurly) FetchResultCtx(ctx context.Context, token string) (res JSResult, e error) {
+	if token == "" || strings.ContainsAny(token, "/?#%") {
+		return res, ErrInvalidResultToken
+	}
+	resp, err := r.createRequest(withOperation(ctx, "fetch_result"), "recurly_js/result/"+token, "GET", nil, nil)
+	if err != nil {
+		return res, err
+	}
+	if resp.StatusCode >= 400 {
+		return res, r.createRecurlyError(resp)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return res, err
+	}
+	r.logResponseBody(ctx, resp, body)
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(body, &root); err != nil {
+		return res, err
+	}
+	switch root.XMLName.Local {
+	case "subscription":
+		sub := r.NewSubscription()
+		res.Subscription, e = &sub, xml.Unmarshal(body, &sub)
+	case "transaction":
+		tran := r.NewTransaction()
+		res.Transaction, e = &tran, xml.Unmarshal(body, &tran)
+	case "billing_info":
+		bi := r.NewBillingInfo()
+		res.BillingInfo, e = &bi, xml.Unmarshal(body, &bi)
+		bi.markLoaded()
+	default:
+		e = fmt.Errorf("Unexpected Recurly.js result %s", root.XMLName.Local)
+	}
+	return res, e
+}
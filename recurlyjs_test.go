@@ -0,0 +1,75 @@
+package gorecurly
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignJS(t *testing.T) {
+	r := NewClient("", WithJSKey("0123456789abcdef0123456789abcdef"))
+	sig, err := r.SignJS(JSParams{
+		"account":      JSParams{"account_code": "a 1"},
+		"subscription": JSParams{"plan_code": "gold", "add_ons": []string{"x", "y"}},
+		"timestamp":    1329942896,
+		"nonce":        "abc",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := "d9f33ce7459104e594d2ff6322b639c28cb489dd|account%5Baccount_code%5D=a+1&nonce=abc&subscription%5Badd_ons%5D%5B%5D=x&subscription%5Badd_ons%5D%5B%5D=y&subscription%5Bplan_code%5D=gold&timestamp=1329942896"
+	if sig != expected {
+		t.Fatalf("Unexpected signature %v", sig)
+	}
+	if params, err := r.VerifyJSSignature(sig, 0); err != nil || params.Get("subscription[plan_code]") != "gold" {
+		t.Fatalf("Expected the signature to verify, got %v %v", params, err)
+	}
+	if _, err := r.VerifyJSSignature(sig, time.Hour); !errors.Is(err, ErrSignatureExpired) {
+		t.Fatalf("Expected the signature to be expired, got %v", err)
+	}
+	if _, err := r.VerifyJSSignature(strings.Replace(sig, "gold", "platinum", 1), 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Expected a tampered signature to be rejected, got %v", err)
+	}
+
+	sig, _ = r.SignTransaction("a1", 1000, "USD")
+	params, err := r.VerifyJSSignature(sig, time.Minute)
+	if err != nil || params.Get("transaction[amount_in_cents]") != "1000" || params.Get("account[account_code]") != "a1" || len(params.Get("nonce")) != 32 {
+		t.Fatalf("Unexpected transaction signature %v %v", params, err)
+	}
+	if _, err := NewClient("").SignBillingInfoUpdate("a1"); !errors.Is(err, ErrNoJSKey) {
+		t.Fatalf("Expected a missing key error, got %v", err)
+	}
+}
+
+func TestFetchResult(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/recurly_js/result/sub":
+			fmt.Fprint(w, `<subscription><uuid>abc</uuid><state>active</state></subscription>`)
+		case "/recurly_js/result/bi":
+			fmt.Fprint(w, `<billing_info><first_name>Verena</first_name></billing_info>`)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL))
+	res, err := r.FetchResult("sub")
+	if err != nil || res.Subscription == nil || res.Subscription.UUID != "abc" || res.Subscription.r != r || res.Transaction != nil {
+		t.Fatalf("Unexpected result %+v %v", res, err)
+	}
+	if res, err = r.FetchResult("bi"); err != nil || res.BillingInfo == nil || res.BillingInfo.FirstName != "Verena" {
+		t.Fatalf("Unexpected result %+v %v", res, err)
+	}
+	if _, err = r.FetchResult("gone"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	if _, err = r.FetchResult("../accounts"); !errors.Is(err, ErrInvalidResultToken) {
+		t.Fatalf("Expected the token to be rejected, got %v", err)
+	}
+}
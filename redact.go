@@ -0,0 +1,102 @@
+package gorecurly
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+//Replacement for redacted values
+const redacted = "[FILTERED]"
+
+//XML fields and query parameters masked in all diagnostic output
+var DefaultRedactedFields = []string{
+	"number",
+	"verification_value",
+	"hosted_login_token",
+	"account_number",
+	"routing_number",
+	"api_key",
+	"private_key",
+}
+
+//Headers that are always masked in diagnostic output
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+//Mask the values of fields in diagnostic output instead of DefaultRedactedFields.
+//Extend the defaults with WithRedactedFields(append(DefaultRedactedFields, "email")...).
+func WithRedactedFields(fields ...string) Option {
+	return func(r *Recurly) {
+		r.redactor = newRedactor(fields)
+	}
+}
+
+//Masks sensitive values before they are logged
+type redactor struct {
+	fields   map[string]bool
+	elements []*regexp.Regexp
+}
+
+func newRedactor(fields []string) *redactor {
+	rd := &redactor{fields: map[string]bool{}}
+	for _, f := range fields {
+		rd.fields[f] = true
+		name := regexp.QuoteMeta(f)
+		rd.elements = append(rd.elements, regexp.MustCompile(`(?s)(<`+name+`(?:\s[^>]*)?>).*?(</`+name+`>)`))
+	}
+	return rd
+}
+
+//Mask the content of redacted XML elements in body
+func (rd *redactor) body(body []byte) string {
+	s := string(body)
+	for _, re := range rd.elements {
+		s = re.ReplaceAllString(s, "${1}"+redacted+"${2}")
+	}
+	return s
+}
+
+//Return a copy of h with credentials masked
+func (rd *redactor) header(h http.Header) http.Header {
+	c := h.Clone()
+	for _, name := range redactedHeaders {
+		if _, ok := c[name]; ok {
+			c.Set(name, redacted)
+		}
+	}
+	return c
+}
+
+//Mask credentials and redacted query parameters in a url
+func (rd *redactor) url(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	q := u.Query()
+	changed := false
+	for k := range q {
+		if rd.fields[strings.ToLower(k)] {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+//Mask the redacted fields of an XML body, for use in your own diagnostic output
+func (r *Recurly) Redact(body []byte) string {
+	return r.redactor.body(body)
+}
+
+//Return a copy of h with credentials masked, for use in your own diagnostic output
+func (r *Recurly) RedactHeader(h http.Header) http.Header {
+	return r.redactor.header(h)
+}
@@ -0,0 +1,156 @@
+package gorecurly
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//Controls how failed requests are retried.  A request is retried when
+//Recurly answers 429 or 5xx, or when the connection fails.  Only GET and HEAD
+//are retried in all of those cases.  POST, PUT and DELETE may have been acted
+//on, e.g. a DELETE of a transaction refunds it, so they are only retried when
+//Recurly cannot have acted on them: a 429 response, or a connection that
+//could not be established.
+type RetryPolicy struct {
+	//Number of retries after the first attempt, 0 disables retrying
+	MaxRetries int
+	//Backoff before the first retry, doubled on every following retry
+	MinBackoff time.Duration
+	//Upper limit for the computed backoff
+	MaxBackoff time.Duration
+	//Longest wait allowed when Recurly asks for one, with Retry-After or
+	//X-RateLimit-Reset on a 429 and with Retry-After on a 5xx.  0 means
+	//MaxBackoff, a negative value means always wait as long as asked.
+	//A 429 asking for longer is returned, a 5xx is retried after this wait.
+	MaxRetryAfter time.Duration
+}
+
+//A sensible policy for batch jobs
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 30 * time.Second,
+}
+
+//Retry failed requests according to policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(r *Recurly) {
+		r.retry = policy
+	}
+}
+
+//Decide if attempt should be retried and how long to wait before doing so
+func (p RetryPolicy) backoff(method string, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+	idempotent := method == "GET" || method == "HEAD"
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, false
+		}
+		if !idempotent && !isDialError(err) {
+			return 0, false
+		}
+		return p.jitter(attempt), true
+	}
+	limit := p.maxRetryAfter()
+	switch {
+	case resp.StatusCode == 429:
+		if wait, ok := retryAfter(resp.Header, true); ok {
+			if limit >= 0 && wait > limit {
+				return 0, false
+			}
+			return wait, true
+		}
+		return p.jitter(attempt), true
+	case resp.StatusCode >= 500 && idempotent:
+		if wait, ok := retryAfter(resp.Header, false); ok {
+			if limit >= 0 && wait > limit {
+				wait = limit
+			}
+			return wait, true
+		}
+		return p.jitter(attempt), true
+	}
+	return 0, false
+}
+
+//The longest wait Recurly may ask for, negative for no limit
+func (p RetryPolicy) maxRetryAfter() time.Duration {
+	if p.MaxRetryAfter != 0 {
+		return p.MaxRetryAfter
+	}
+	if p.MaxBackoff <= 0 {
+		return -1
+	}
+	return p.MaxBackoff
+}
+
+//Exponential backoff for attempt with jitter in the upper half of the interval
+func (p RetryPolicy) jitter(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 0; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+//Read how long Recurly wants us to wait from Retry-After, or from X-RateLimit-Reset
+//when rateLimited.  Recurly sends X-RateLimit-Reset on every response, it only
+//tells how long to wait when the request was refused for the rate limit.
+func retryAfter(h http.Header, rateLimited bool) (time.Duration, bool) {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(time.Until(t)), true
+		}
+	}
+	if v := h.Get("X-RateLimit-Reset"); rateLimited && v != "" {
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return nonNegative(time.Until(time.Unix(epoch, 0))), true
+		}
+	}
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+//A dial error means the request never reached Recurly
+func isDialError(err error) bool {
+	var operr *net.OpError
+	return errors.As(err, &operr) && operr.Op == "dial"
+}
+
+//Wait for d or until ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
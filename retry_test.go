@@ -0,0 +1,105 @@
+package gorecurly
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		fmt.Fprintf(w, "%s", accountGet)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL), WithRetryPolicy(testRetryPolicy))
+	if acc, e := r.GetAccount("test21"); e != nil {
+		t.Fatal(e.Error())
+	} else if acc.AccountCode != "test21" {
+		t.Fatal("Could not parse the account object correctly")
+	}
+	if calls != 3 {
+		t.Fatalf("Expected 3 attempts, got %v", calls)
+	}
+}
+
+func TestRetryPostOnlyWhenSafe(t *testing.T) {
+	calls := 0
+	status := 503
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprintf(w, "%s", accountCreate)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL), WithRetryPolicy(testRetryPolicy))
+	acc := r.NewAccount()
+	acc.AccountCode = "abcdef1234567890"
+	if e := acc.Create(); e == nil || calls != 1 {
+		t.Fatalf("A POST answered with 503 should not be retried, attempts: %v", calls)
+	}
+
+	calls = 0
+	status = 429
+	acc = r.NewAccount()
+	acc.AccountCode = "abcdef1234567890"
+	if e := acc.Create(); e != nil {
+		t.Fatal(e.Error())
+	}
+	if calls != 2 {
+		t.Fatalf("A POST answered with 429 should be retried, attempts: %v", calls)
+	}
+}
+
+func TestRetryDeleteOnlyWhenSafe(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(502)
+	}))
+	defer ts.Close()
+
+	r := NewClient("", WithBaseURL(ts.URL), WithRetryPolicy(testRetryPolicy))
+	tran := r.NewTransaction()
+	tran.UUID = "abc"
+	if e := tran.Refund(100); e == nil || calls != 1 {
+		t.Fatalf("A DELETE answered with 502 should not be retried, attempts: %v", calls)
+	}
+}
+
+func TestRetryWaits(t *testing.T) {
+	reset := fmt.Sprint(time.Now().Add(time.Hour).Unix())
+	policy := RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	h := http.Header{"X-Ratelimit-Reset": {reset}}
+	//the rate limit reset only matters for a 429
+	if wait, ok := policy.backoff("GET", 0, &http.Response{StatusCode: 503, Header: h}, nil); !ok || wait > 5*time.Millisecond {
+		t.Fatalf("Expected a short retry of the 503, got %v %v", wait, ok)
+	}
+	if _, ok := policy.backoff("GET", 0, &http.Response{StatusCode: 429, Header: h}, nil); ok {
+		t.Fatal("A 429 asking for a wait beyond the limit should not be retried")
+	}
+	//Retry-After of a 5xx is capped
+	h = http.Header{"Retry-After": {"3600"}}
+	if wait, ok := policy.backoff("GET", 0, &http.Response{StatusCode: 503, Header: h}, nil); !ok || wait != 5*time.Millisecond {
+		t.Fatalf("Expected the wait to be capped, got %v %v", wait, ok)
+	}
+	policy.MaxRetryAfter = -1
+	if wait, ok := policy.backoff("GET", 0, &http.Response{StatusCode: 503, Header: h}, nil); !ok || wait != time.Hour {
+		t.Fatalf("Expected the full wait, got %v %v", wait, ok)
+	}
+}
@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"time"
@@ -36,6 +37,12 @@ type Subscription struct {
 	TotalBillingCycles     string          `xml:"total_billing_cycles,omitempty"`
 }
 
+//Subscription Stub struct
+type SubscriptionStub struct {
+	XMLName xml.Name `xml:"subscription"`
+	stub
+}
+
 type subscriptionCreate struct {
 	XMLName            xml.Name        `xml:"subscription"`
 	PlanCode           string          `xml:"plan_code,omitempty"`
@@ -63,7 +70,7 @@ type subscriptionUpdate struct {
 //Attach an existing account to the subscription before creating it
 func (s *Subscription) AttachExistingAccount(a Account) (e error) {
 	if s.UUID != "" {
-		return RecurlyError{statusCode: 400, Description: "Subscription Already in Use and can't attach another account to it"}
+		return &APIError{StatusCode: 400, Description: "Subscription Already in Use and can't attach another account to it"}
 	}
 	s.EmbedAccount = new(Account)
 	s.EmbedAccount.AccountCode = a.AccountCode
@@ -74,7 +81,7 @@ func (s *Subscription) AttachExistingAccount(a Account) (e error) {
 //Attach a new account object to a subscription.  The account will be created along with the subscription
 func (s *Subscription) AttachAccount(a Account) (e error) {
 	if s.UUID != "" {
-		return RecurlyError{statusCode: 400, Description: "Subscription Already in Use and can't attach another account to it"}
+		return &APIError{StatusCode: 400, Description: "Subscription Already in Use and can't attach another account to it"}
 	}
 	s.EmbedAccount = new(Account)
 	a.CreatedAt = nil
@@ -87,8 +94,18 @@ func (s *Subscription) AttachAccount(a Account) (e error) {
 
 //Create an account
 func (s *Subscription) Create() error {
+	return s.CreateCtx(context.Background())
+}
+
+//Same as Create, bound to a context
+func (s *Subscription) CreateCtx(ctx context.Context) error {
 	if s.UUID != "" {
-		return RecurlyError{statusCode: 400, Description: "Subscription Already in Use"}
+		return &APIError{StatusCode: 400, Description: "Subscription Already in Use"}
+	}
+	if s.EmbedAccount != nil {
+		if err := s.EmbedAccount.B.validate(); err != nil {
+			return err
+		}
 	}
 	t := new(time.Time)
 	decode, err := s.TrialEndsAt.GetDate()
@@ -110,7 +127,7 @@ func (s *Subscription) Create() error {
 	}
 	//Hack here need to investigate why the create causes the return to append
 	s.SubscriptionAddOns = EmbedPlanAddOns{}
-	if err := s.r.doCreateReturn(sc, &s, s.endpoint); err == nil {
+	if err := s.r.doCreateReturn(ctx, sc, &s, s.endpoint); err == nil {
 		return nil
 	} else {
 		return err
@@ -120,6 +137,11 @@ func (s *Subscription) Create() error {
 
 //Update an account
 func (s *Subscription) Update(now bool) error {
+	return s.UpdateCtx(context.Background(), now)
+}
+
+//Same as Update, bound to a context
+func (s *Subscription) UpdateCtx(ctx context.Context, now bool) error {
 	sub := subscriptionUpdate{
 		PlanCode:           s.PlanCode,
 		UnitAmountInCents:  s.UnitAmountInCents,
@@ -133,41 +155,71 @@ func (s *Subscription) Update(now bool) error {
 	}
 	//Hack here need to investigate why the update causes the return to append
 	s.SubscriptionAddOns = EmbedPlanAddOns{}
-	return s.r.doUpdateReturn(sub, &s, s.endpoint+"/"+s.UUID)
+	return s.r.doUpdateReturn(ctx, sub, &s, s.endpoint+"/"+s.UUID)
 }
 
 //Reactivate a cancelled account
 func (s *Subscription) Reactivate() error {
-	return s.r.doUpdateReturn(nil, &s, s.endpoint+"/"+s.UUID+"/reactivate")
+	return s.ReactivateCtx(context.Background())
+}
+
+//Same as Reactivate, bound to a context
+func (s *Subscription) ReactivateCtx(ctx context.Context) error {
+	return s.r.doUpdateReturn(ctx, nil, &s, s.endpoint+"/"+s.UUID+"/reactivate")
 }
 
 //Cancel an account
 func (s *Subscription) Cancel() error {
-	return s.r.doUpdateReturn(nil, &s, s.endpoint+"/"+s.UUID+"/cancel")
+	return s.CancelCtx(context.Background())
+}
+
+//Same as Cancel, bound to a context
+func (s *Subscription) CancelCtx(ctx context.Context) error {
+	return s.r.doUpdateReturn(ctx, nil, &s, s.endpoint+"/"+s.UUID+"/cancel")
 }
 
 //Postpone an accounts renewal datetime
 func (s *Subscription) Postpone(renewal time.Time) error {
-	return s.r.doUpdateReturn(nil, &s, s.endpoint+"/"+s.UUID+"/postpone?next_renewal_date=" + renewal.Format(time.RFC3339))
+	return s.PostponeCtx(context.Background(), renewal)
+}
+
+//Same as Postpone, bound to a context
+func (s *Subscription) PostponeCtx(ctx context.Context, renewal time.Time) error {
+	return s.r.doUpdateReturn(ctx, nil, &s, s.endpoint+"/"+s.UUID+"/postpone?next_renewal_date=" + renewal.Format(time.RFC3339))
 }
 
-func (s *Subscription) terminate(refund string) error {
-	return s.r.doUpdateReturn(nil, &s, s.endpoint+"/"+s.UUID+"/terminate?refund="+refund)
+func (s *Subscription) terminate(ctx context.Context, refund string) error {
+	return s.r.doUpdateReturn(ctx, nil, &s, s.endpoint+"/"+s.UUID+"/terminate?refund="+refund)
 }
 
 //Terminate with full refund of the last charge for the current subscription term.
 func (s *Subscription) TerminateWithFullRefund() error {
-	return s.terminate("full")
+	return s.TerminateWithFullRefundCtx(context.Background())
+}
+
+//Same as TerminateWithFullRefund, bound to a context
+func (s *Subscription) TerminateWithFullRefundCtx(ctx context.Context) error {
+	return s.terminate(ctx, "full")
 }
 
 //Terminate and Prorates a refund based on the amount of time remaining in the current bill cycle.
 func (s *Subscription) TerminateWithPartialRefund() error {
-	return s.terminate("partial")
+	return s.TerminateWithPartialRefundCtx(context.Background())
+}
+
+//Same as TerminateWithPartialRefund, bound to a context
+func (s *Subscription) TerminateWithPartialRefundCtx(ctx context.Context) error {
+	return s.terminate(ctx, "partial")
 }
 
 //Terminate without refund
 func (s *Subscription) Terminate() error {
-	return s.terminate("none")
+	return s.TerminateCtx(context.Background())
+}
+
+//Same as Terminate, bound to a context
+func (s *Subscription) TerminateCtx(ctx context.Context) error {
+	return s.terminate(ctx, "none")
 }
 
 
@@ -239,3 +291,9 @@ type EmbedPlanAddOn struct {
 	Quantity          int    `xml:"quantity,omitempty"`
 	UnitAmountInCents int    `xml:"unit_amount_in_cents,omitempty"`
 }
+
+//Bind the subscription to a client after it is decoded from a list
+func (s *Subscription) attach(r *Recurly) {
+	s.r = r
+	s.endpoint = SUBSCRIPTIONS
+}
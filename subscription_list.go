@@ -1,7 +1,10 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
+	"iter"
+	"net/url"
 )
 
 //Subscription pager
@@ -14,32 +17,38 @@ type SubscriptionList struct {
 
 //Get next set of subscriptions
 func (s *SubscriptionList) Next() bool {
-	if s.next != "" {
-		*s, _ = s.r.GetSubscriptions(s.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return s.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (s *SubscriptionList) NextCtx(ctx context.Context) bool {
+	return movePage(s, s.next, func() (SubscriptionList, error) {
+		return s.r.GetSubscriptionsCtx(ctx, s.NextParams())
+	})
 }
 
 //Get previous set of subscriptions
 func (s *SubscriptionList) Prev() bool {
-	if s.prev != "" {
-		*s, _ = s.r.GetSubscriptions(s.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return s.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (s *SubscriptionList) PrevCtx(ctx context.Context) bool {
+	return movePage(s, s.prev, func() (SubscriptionList, error) {
+		return s.r.GetSubscriptionsCtx(ctx, s.PrevParams())
+	})
 }
 
 //Go to start set of subscriptions
 func (s *SubscriptionList) Start() bool {
-	if s.prev != "" {
-		*s, _ = s.r.GetSubscriptions(s.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return s.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (s *SubscriptionList) StartCtx(ctx context.Context) bool {
+	return movePage(s, s.prev, func() (SubscriptionList, error) {
+		return s.r.GetSubscriptionsCtx(ctx, s.StartParams())
+	})
 }
 
 //List of subscriptions for an account
@@ -48,36 +57,54 @@ type AccountSubscriptionList struct {
 	r *Recurly
 	XMLName xml.Name `xml:"subscriptions"`
 	AccountCode string `xml:"-"`
-	Subscriptions []Subscription `xml:"subscriptions"`
+	Subscriptions []Subscription `xml:"subscription"`
 }
 
 
 //Get next set of subscriptions
 func (a *AccountSubscriptionList) Next() (bool) {
-	if a.next != "" {
-		*a,_ = a.r.GetAccountSubscriptions(a.AccountCode,a.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return a.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (a *AccountSubscriptionList) NextCtx(ctx context.Context) bool {
+	return movePage(a, a.next, func() (AccountSubscriptionList, error) {
+		return a.r.GetAccountSubscriptionsCtx(ctx, a.AccountCode,a.NextParams())
+	})
 }
 
 //Get previous set of subscriptions
 func (a *AccountSubscriptionList) Prev() ( bool) {
-	if a.prev != "" {
-		*a,_ = a.r.GetAccountSubscriptions(a.AccountCode,a.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return a.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (a *AccountSubscriptionList) PrevCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AccountSubscriptionList, error) {
+		return a.r.GetAccountSubscriptionsCtx(ctx, a.AccountCode,a.PrevParams())
+	})
 }
 
 //Go to start set of subscriptions
 func (a *AccountSubscriptionList) Start() ( bool) {
-	if a.prev != "" {
-		*a,_ = a.r.GetAccountSubscriptions(a.AccountCode,a.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return a.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (a *AccountSubscriptionList) StartCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AccountSubscriptionList, error) {
+		return a.r.GetAccountSubscriptionsCtx(ctx, a.AccountCode,a.StartParams())
+	})
+}
+
+//Iterate over all subscriptions, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) Subscriptions(ctx context.Context, params ...url.Values) iter.Seq2[Subscription, error] {
+	return listAll[Subscription](ctx, r, SUBSCRIPTIONS, params)
+}
+
+//Iterate over all subscriptions for an account_code, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) AccountSubscriptions(ctx context.Context, account_code string, params ...url.Values) iter.Seq2[Subscription, error] {
+	return listAll[Subscription](ctx, r, ACCOUNTS+"/"+account_code+"/"+SUBSCRIPTIONS, params)
 }
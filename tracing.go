@@ -0,0 +1,46 @@
+package gorecurly
+
+import (
+	"context"
+)
+
+//A Tracer starts a span for every api call.  The span's context is used for
+//the requests of the call, so spans nest under the caller's trace.
+//The otelrecurly package provides an OpenTelemetry Tracer.
+type Tracer interface {
+	Start(ctx context.Context, info RequestInfo) (context.Context, Span)
+}
+
+//A Span traces one api call, including its retries
+type Span interface {
+	//Record the response of an attempt
+	SetResponse(status int, requestID string)
+	//Finish the span, err is the error returned by the call
+	End(err error)
+}
+
+//Trace every api call with tracer
+func WithTracer(tracer Tracer) Option {
+	return func(r *Recurly) {
+		r.tracer = tracer
+	}
+}
+
+type spanKey struct{}
+
+//Start a span for an api call, unless there is no tracer or ctx already is in
+//a call's span.  The returned function ends the span and must always be called.
+func (r *Recurly) startSpan(ctx context.Context, method, endpoint string) (context.Context, func(error)) {
+	if r.tracer == nil || ctx.Value(spanKey{}) != nil {
+		return ctx, func(error) {}
+	}
+	ctx, span := r.tracer.Start(ctx, newRequestInfo(ctx, method, endpoint))
+	return context.WithValue(ctx, spanKey{}, span), span.End
+}
+
+//Record the response of an attempt on the span of ctx
+func setSpanResponse(ctx context.Context, status int, requestID string) {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		span.SetResponse(status, requestID)
+	}
+}
@@ -1,7 +1,10 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
+	"iter"
+	"net/url"
 )
 
 
@@ -15,32 +18,38 @@ type TransactionList struct {
 
 //Get next set of transactions
 func (t *TransactionList) Next() bool {
-	if t.next != "" {
-		*t, _ = t.r.GetTransactions(t.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return t.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (t *TransactionList) NextCtx(ctx context.Context) bool {
+	return movePage(t, t.next, func() (TransactionList, error) {
+		return t.r.GetTransactionsCtx(ctx, t.NextParams())
+	})
 }
 
 //Get previous set of transactions
 func (t *TransactionList) Prev() bool {
-	if t.prev != "" {
-		*t, _ = t.r.GetTransactions(t.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return t.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (t *TransactionList) PrevCtx(ctx context.Context) bool {
+	return movePage(t, t.prev, func() (TransactionList, error) {
+		return t.r.GetTransactionsCtx(ctx, t.PrevParams())
+	})
 }
 
 //Go to start set of transactions
 func (t *TransactionList) Start() bool {
-	if t.prev != "" {
-		*t, _ = t.r.GetTransactions(t.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return t.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (t *TransactionList) StartCtx(ctx context.Context) bool {
+	return movePage(t, t.prev, func() (TransactionList, error) {
+		return t.r.GetTransactionsCtx(ctx, t.StartParams())
+	})
 }
 
 
@@ -56,31 +65,48 @@ type AccountTransactionList struct {
 
 //Get next set of transactions
 func (a *AccountTransactionList) Next() (bool) {
-	if a.next != "" {
-		*a,_ = a.r.GetAccountTransactions(a.AccountCode,a.NextParams())
-	} else {
-		return false
-	}
-	return true
+	return a.NextCtx(context.Background())
+}
+
+//Same as Next, bound to a context
+func (a *AccountTransactionList) NextCtx(ctx context.Context) bool {
+	return movePage(a, a.next, func() (AccountTransactionList, error) {
+		return a.r.GetAccountTransactionsCtx(ctx, a.AccountCode,a.NextParams())
+	})
 }
 
 //Get previous set of transactions
 func (a *AccountTransactionList) Prev() ( bool) {
-	if a.prev != "" {
-		*a,_ = a.r.GetAccountTransactions(a.AccountCode,a.PrevParams())
-	} else {
-		return false
-	}
-	return true
+	return a.PrevCtx(context.Background())
+}
+
+//Same as Prev, bound to a context
+func (a *AccountTransactionList) PrevCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AccountTransactionList, error) {
+		return a.r.GetAccountTransactionsCtx(ctx, a.AccountCode,a.PrevParams())
+	})
 }
 
 //Go to start set of transactions
 func (a *AccountTransactionList) Start() ( bool) {
-	if a.prev != "" {
-		*a,_ = a.r.GetAccountTransactions(a.AccountCode,a.StartParams())
-	} else {
-		return false
-	}
-	return true
+	return a.StartCtx(context.Background())
+}
+
+//Same as Start, bound to a context
+func (a *AccountTransactionList) StartCtx(ctx context.Context) bool {
+	return movePage(a, a.prev, func() (AccountTransactionList, error) {
+		return a.r.GetAccountTransactionsCtx(ctx, a.AccountCode,a.StartParams())
+	})
 }
 
+//Iterate over all transactions, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) Transactions(ctx context.Context, params ...url.Values) iter.Seq2[Transaction, error] {
+	return listAll[Transaction](ctx, r, TRANSACTIONS, params)
+}
+
+//Iterate over all transactions for an account_code, following the cursor from page to page.
+//Iteration stops after the first error is yielded.
+func (r *Recurly) AccountTransactions(ctx context.Context, account_code string, params ...url.Values) iter.Seq2[Transaction, error] {
+	return listAll[Transaction](ctx, r, ACCOUNTS+"/"+account_code+"/"+TRANSACTIONS, params)
+}
@@ -1,6 +1,7 @@
 package gorecurly
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"time"
@@ -8,28 +9,31 @@ import (
 
 //Transaction Object
 type Transaction struct {
-	XMLName  xml.Name `xml:"transaction"`
-	endpoint string
-	r        *Recurly
-	Account  *AccountStub `xml:"account,omitempty"`
-	//Invoice *InvoiceStub `xml:"invoice,omitempty"`
-	//Subscription *SubscriptionStub `xml:"subscription,omitempty"`
-	EmbedAccount    *Account `xml:"-"`
-	UUID            string   `xml:"uuid,omitempty"`
-	Action          string   `xml:"action,omitempty"`
-	State           string   `xml:"state,omitempty"`
-	AmountInCents   int      `xml:"amount_in_cents,omitempty"`
-	TaxInCents      int      `xml:"tax_in_cents,omitempty"`
-	Currency        string   `xml:"currency,omitempty"`
-	Status          string   `xml:"status,omitempty"`
-	Reference       string   `xml:"reference,omitempty"`
-	Test            bool     `xml:"test,omitempty"`
-	Voidable        bool     `xml:"voidable,omitempty"`
-	Refundable      bool     `xml:"refundable,omitempty"`
-	CVVResult       string   `xml:"cvv_result,omitempty"`
-	AVSResult       string   `xml:"avs_result,omitempty"`
-	AVSResultStreet string   `xml:"avs_result_street,omitempty"`
-	AVSResultPostal string   `xml:"avs_result_postal,omitempty"`
+	XMLName         xml.Name `xml:"transaction"`
+	endpoint        string
+	r               *Recurly
+	Account         *AccountStub      `xml:"account,omitempty"`
+	Invoice         *InvoiceStub      `xml:"invoice,omitempty"`
+	Subscription    *SubscriptionStub `xml:"subscription,omitempty"`
+	EmbedAccount    *Account          `xml:"-"`
+	UUID            string            `xml:"uuid,omitempty"`
+	Action          string            `xml:"action,omitempty"`
+	State           string            `xml:"state,omitempty"`
+	AmountInCents   int               `xml:"amount_in_cents,omitempty"`
+	TaxInCents      int               `xml:"tax_in_cents,omitempty"`
+	Currency        string            `xml:"currency,omitempty"`
+	Status          string            `xml:"status,omitempty"`
+	Reference       string            `xml:"reference,omitempty"`
+	Test            bool              `xml:"test,omitempty"`
+	Voidable        bool              `xml:"voidable,omitempty"`
+	Refundable      bool              `xml:"refundable,omitempty"`
+	CVVResult       string            `xml:"cvv_result,omitempty"`
+	AVSResult       string            `xml:"avs_result,omitempty"`
+	AVSResultStreet string            `xml:"avs_result_street,omitempty"`
+	AVSResultPostal string            `xml:"avs_result_postal,omitempty"`
+	PaymentMethod   string            `xml:"payment_method,omitempty"`
+	Source          string            `xml:"source,omitempty"`
+	Recurring       bool              `xml:"recurring,omitempty"`
 	//Details not implemented
 	CreatedAt *time.Time `xml:"created_at,omitempty"`
 }
@@ -44,7 +48,7 @@ type transactionCreate struct {
 //Attach an existing account to a transaction
 func (t *Transaction) AttachExistingAccount(a Account) (e error) {
 	if t.UUID != "" {
-		return RecurlyError{statusCode: 400, Description: "Subscription Already in Use and can't attach another account to it"}
+		return &APIError{StatusCode: 400, Description: "Subscription Already in Use and can't attach another account to it"}
 	}
 	t.EmbedAccount = new(Account)
 	t.EmbedAccount.AccountCode = a.AccountCode
@@ -54,7 +58,7 @@ func (t *Transaction) AttachExistingAccount(a Account) (e error) {
 //Attach a new account to a transaction
 func (t *Transaction) AttachAccount(a Account) (e error) {
 	if t.UUID != "" {
-		return RecurlyError{statusCode: 400, Description: "Subscription Already in Use and can't attach another account to it"}
+		return &APIError{StatusCode: 400, Description: "Subscription Already in Use and can't attach another account to it"}
 	}
 	t.EmbedAccount = new(Account)
 	a.CreatedAt = nil
@@ -67,15 +71,25 @@ func (t *Transaction) AttachAccount(a Account) (e error) {
 
 //Create a transaction
 func (t *Transaction) Create() error {
+	return t.CreateCtx(context.Background())
+}
+
+//Same as Create, bound to a context
+func (t *Transaction) CreateCtx(ctx context.Context) error {
 	if t.UUID != "" {
-		return RecurlyError{statusCode: 400, Description: "Subscription Already in Use"}
+		return &APIError{StatusCode: 400, Description: "Subscription Already in Use"}
+	}
+	if t.EmbedAccount != nil {
+		if err := t.EmbedAccount.B.validate(); err != nil {
+			return err
+		}
 	}
 	tc := transactionCreate{
 		Account:       t.EmbedAccount,
 		Currency:      t.Currency,
 		AmountInCents: t.AmountInCents,
 	}
-	if err := t.r.doCreateReturn(tc, &t, t.endpoint); err == nil {
+	if err := t.r.doCreateReturn(ctx, tc, &t, t.endpoint); err == nil {
 		return nil
 	} else {
 		return err
@@ -83,12 +97,31 @@ func (t *Transaction) Create() error {
 	return nil
 }
 
-//Refund a partial amount from a transaction 
+//Refund a partial amount from a transaction
+//
+//Deprecated: Recurly replaced transaction refunds with invoice refunds, use Invoice.RefundAmount.
 func (t *Transaction) Refund(amount int) error {
-	return t.r.doDelete(t.endpoint + "/" + t.UUID + "?amount_in_cents=" + fmt.Sprintf("%v",amount))
+	return t.RefundCtx(context.Background(), amount)
+}
+
+//Same as Refund, bound to a context
+func (t *Transaction) RefundCtx(ctx context.Context, amount int) error {
+	return t.r.doDelete(ctx, t.endpoint + "/" + t.UUID + "?amount_in_cents=" + fmt.Sprintf("%v",amount))
 }
 //Completely refund a transaction
+//
+//Deprecated: Recurly replaced transaction refunds with invoice refunds, use Invoice.RefundAmount.
 func (t *Transaction) RefundAll() error {
-	return t.r.doDelete(t.endpoint + "/" + t.UUID)
+	return t.RefundAllCtx(context.Background())
+}
+
+//Same as RefundAll, bound to a context
+func (t *Transaction) RefundAllCtx(ctx context.Context) error {
+	return t.r.doDelete(ctx, t.endpoint + "/" + t.UUID)
 }
 
+//Bind the transaction to a client after it is decoded from a list
+func (t *Transaction) attach(r *Recurly) {
+	t.r = r
+	t.endpoint = TRANSACTIONS
+}
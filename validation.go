@@ -0,0 +1,133 @@
+package gorecurly
+
+import (
+	"reflect"
+	"strings"
+)
+
+//The resource types a recurly field name can start with
+var validationRoots = map[string]reflect.Type{
+	"account":             reflect.TypeOf(Account{}),
+	"add_on":              reflect.TypeOf(PlanAddOn{}),
+	"adjustment":          reflect.TypeOf(Adjustment{}),
+	"billing_info":        reflect.TypeOf(BillingInfo{}),
+	"coupon":              reflect.TypeOf(Coupon{}),
+	"invoice":             reflect.TypeOf(Invoice{}),
+	"plan":                reflect.TypeOf(Plan{}),
+	"redemption":          reflect.TypeOf(Redemption{}),
+	"subscription":        reflect.TypeOf(Subscription{}),
+	"subscription_add_on": reflect.TypeOf(EmbedPlanAddOn{}),
+	"transaction":         reflect.TypeOf(Transaction{}),
+}
+
+//Fields that are sent under a recurly name but not unmarshalled from it
+var validationOverrides = map[reflect.Type]map[string]string{
+	reflect.TypeOf(Subscription{}): {
+		"account":            "EmbedAccount",
+		"plan_code":          "PlanCode",
+		"coupon_code":        "CouponCode",
+		"starts_at":          "StartsAt",
+		"first_renewal_date": "FirstRenewalDate",
+	},
+	reflect.TypeOf(Transaction{}): {
+		"account": "EmbedAccount",
+	},
+}
+
+//Return the errors for a field.  field is a recurly field name and also
+//matches the end of a nested one, so "billing_info.number" matches
+//"subscription.account.billing_info.number".
+func (r RecurlyValidationErrors) ForField(field string) (errs []RecurlyValidationError) {
+	for _, e := range r.Errors {
+		if e.FieldName == field || strings.HasSuffix(e.FieldName, "."+field) {
+			errs = append(errs, e)
+		}
+	}
+	return
+}
+
+//Return the errors for a Go struct field path as returned by StructField.
+//Like ForField it also matches the end of a path, so "B.Number" matches
+//"Account.B.Number" and "Subscription.EmbedAccount.B.Number".
+func (r RecurlyValidationErrors) ForStructField(path string) (errs []RecurlyValidationError) {
+	for _, e := range r.Errors {
+		if f := e.StructField(); f != "" && (f == path || strings.HasSuffix(f, "."+path)) {
+			errs = append(errs, e)
+		}
+	}
+	return
+}
+
+//Return the recurly field names with errors, each once in the order received
+func (r RecurlyValidationErrors) Fields() (fields []string) {
+	seen := map[string]bool{}
+	for _, e := range r.Errors {
+		if !seen[e.FieldName] {
+			seen[e.FieldName] = true
+			fields = append(fields, e.FieldName)
+		}
+	}
+	return
+}
+
+//Report if any of the errors has symbol, e.g. "taken" or "invalid_email"
+func (r RecurlyValidationErrors) Has(symbol string) bool {
+	for _, e := range r.Errors {
+		if e.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+//Map the recurly field name back to the Go struct fields it came from,
+//"subscription.account.billing_info.year" becomes "Subscription.EmbedAccount.B.Year".
+//The path stops at the last part that has a struct field, e.g. "account.base"
+//becomes "Account".  An empty string is returned for an unknown resource.
+func (e RecurlyValidationError) StructField() string {
+	parts := strings.Split(e.FieldName, ".")
+	t, ok := validationRoots[parts[0]]
+	if !ok {
+		return ""
+	}
+	path := []string{t.Name()}
+	for _, part := range parts[1:] {
+		field, ok := structFieldByXMLName(t, part)
+		if !ok {
+			break
+		}
+		path = append(path, field.Name)
+		t = field.Type
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			break
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+//Find the field of t that is marshalled as name
+func structFieldByXMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	if goname, ok := validationOverrides[t][name]; ok {
+		return t.FieldByName(goname)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if found, ok := structFieldByXMLName(f.Type, name); ok {
+				return found, true
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("xml"), ",")[0]
+		if tag == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}